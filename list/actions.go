@@ -0,0 +1,262 @@
+package list
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Action is a named operation on an item, such as opening or deleting it.
+type Action struct {
+	// Name is shown in the action menu.
+	Name string
+
+	// Key, if set, runs the action on the selected item without opening the
+	// menu, and is shown in the help. The list's own keybindings take
+	// precedence, so an action key only works if it isn't bound to an
+	// enabled keybinding in the KeyMap.
+	Key key.Binding
+}
+
+// ActionItem is an item that declares the actions available for it.
+type ActionItem interface {
+	Item
+
+	// Actions returns the actions available for the item.
+	Actions() []Action
+}
+
+// ActionDelegate is a delegate that declares actions for the items it
+// renders. They're listed before the item's own actions.
+type ActionDelegate interface {
+	ItemDelegate
+
+	// Actions returns the actions available for the given item.
+	Actions(item Item) []Action
+}
+
+// ActionMsg is sent when an action is chosen for an item, from the action
+// menu or with the action's key.
+type ActionMsg struct {
+	Action Action
+	Item   Item
+
+	// Index is the index of the item in the unfiltered list of items. This
+	// value can be used with SetItem.
+	Index int
+}
+
+// SelectedActions returns the actions available for the selected item.
+func (m Model) SelectedActions() []Action {
+	item := m.SelectedItem()
+	if item == nil {
+		return nil
+	}
+
+	var actions []Action
+	if d, ok := m.delegate.(ActionDelegate); ok {
+		actions = append(actions, d.Actions(item)...)
+	}
+	if i, ok := item.(ActionItem); ok {
+		actions = append(actions, i.Actions()...)
+	}
+	return actions
+}
+
+// OpenActionMenu opens the menu of actions for the selected item, if it has
+// any.
+func (m *Model) OpenActionMenu() {
+	if len(m.SelectedActions()) == 0 {
+		return
+	}
+	m.actionMenuOpen = true
+	m.actionCursor = 0
+	m.updateKeybindings()
+}
+
+// CloseActionMenu closes the action menu.
+func (m *Model) CloseActionMenu() {
+	m.actionMenuOpen = false
+	m.updateKeybindings()
+}
+
+// ActionMenuOpen returns whether the action menu is open.
+func (m Model) ActionMenuOpen() bool {
+	return m.actionMenuOpen
+}
+
+// runAction returns a command that reports the given action being chosen
+// for the selected item.
+func (m Model) runAction(a Action) tea.Cmd {
+	item, index := m.SelectedItem(), m.GlobalIndex()
+	return func() tea.Msg {
+		return ActionMsg{Action: a, Item: item, Index: index}
+	}
+}
+
+// matchAction returns a command running the action of the selected item
+// whose key matches the given key, if any.
+func (m Model) matchAction(msg tea.KeyMsg) tea.Cmd {
+	for _, a := range m.SelectedActions() {
+		if key.Matches(msg, a.Key) {
+			return m.runAction(a)
+		}
+	}
+	return nil
+}
+
+// handleActionMenu handles keys while the action menu is open.
+func (m *Model) handleActionMenu(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	actions := m.SelectedActions()
+	if len(actions) == 0 {
+		m.CloseActionMenu()
+		return nil
+	}
+	m.actionCursor = max(0, min(len(actions)-1, m.actionCursor))
+
+	switch {
+	case key.Matches(keyMsg, m.KeyMap.CloseActionMenu):
+		m.CloseActionMenu()
+
+	case key.Matches(keyMsg, m.KeyMap.CursorUp):
+		m.actionCursor = (m.actionCursor + len(actions) - 1) % len(actions)
+
+	case key.Matches(keyMsg, m.KeyMap.CursorDown):
+		m.actionCursor = (m.actionCursor + 1) % len(actions)
+
+	case key.Matches(keyMsg, m.KeyMap.ChooseAction):
+		m.CloseActionMenu()
+		return m.runAction(actions[m.actionCursor])
+
+	default:
+		if cmd := m.matchAction(keyMsg); cmd != nil {
+			m.CloseActionMenu()
+			return cmd
+		}
+	}
+	return nil
+}
+
+// actionHelp returns the keys of the selected item's actions for the help
+// view. Keys without help text are described by the action's name.
+func (m Model) actionHelp() []key.Binding {
+	var kb []key.Binding
+	for _, a := range m.SelectedActions() {
+		b := a.Key
+		if len(b.Keys()) == 0 {
+			continue
+		}
+		if b.Help().Key == "" {
+			b.SetHelp(b.Keys()[0], a.Name)
+		}
+		kb = append(kb, b)
+	}
+	return kb
+}
+
+// actionMenuView renders the action menu.
+func (m Model) actionMenuView() string {
+	actions := m.SelectedActions()
+
+	var nameWidth int
+	for _, a := range actions {
+		nameWidth = max(nameWidth, ansi.StringWidth(a.Name))
+	}
+
+	lines := make([]string, len(actions))
+	for i, a := range actions {
+		style := m.Styles.ActionMenuItem
+		if i == m.actionCursor {
+			style = m.Styles.ActionMenuSelectedItem
+		}
+		line := style.Render(a.Name + strings.Repeat(" ", nameWidth-ansi.StringWidth(a.Name)))
+
+		keyHelp := a.Key.Help().Key
+		if keyHelp == "" && len(a.Key.Keys()) > 0 {
+			keyHelp = a.Key.Keys()[0]
+		}
+		if keyHelp != "" {
+			line += "  " + m.Styles.ActionMenuKey.Render(keyHelp)
+		}
+		lines[i] = line
+	}
+	return m.Styles.ActionMenu.Render(strings.Join(lines, "\n"))
+}
+
+// actionMenuOverlay draws the action menu over the given rendering of the
+// items in view, below the selected item, or above it if there's more room
+// there. In grid layout the menu is aligned with the selected cell.
+func (m Model) actionMenuOverlay(items string) string {
+	menu := m.actionMenuView()
+	menuHeight := lipgloss.Height(menu)
+
+	y := 0
+	m.eachItemInView(func(index, top, height int) bool {
+		if index != m.Index() {
+			return true
+		}
+		y = top + height
+		if below := m.itemsHeight - y; below < menuHeight && top > below {
+			y = max(0, top-menuHeight)
+		}
+		return false
+	})
+	return overlay(items, menu, m.gridColumnOf(m.Index())*m.gridCellWidth, y)
+}
+
+// overlay draws fg over bg with its top-left corner at the given column and
+// row.
+func overlay(bg, fg string, x, y int) string {
+	lines := strings.Split(bg, "\n")
+	for i, line := range strings.Split(fg, "\n") {
+		row := y + i
+		if row < 0 || row >= len(lines) {
+			continue
+		}
+
+		left := ansi.Truncate(lines[row], x, "")
+		if pad := x - ansi.StringWidth(left); pad > 0 {
+			left += strings.Repeat(" ", pad)
+		}
+		if strings.Contains(left, "\x1b[") {
+			// Don't let the styles of bg leak into fg.
+			left += "\x1b[0m"
+		}
+		right := cutLeft(lines[row], x+ansi.StringWidth(line))
+		lines[row] = left + line + right
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cutLeft removes the first n columns of s, keeping its escape sequences so
+// that the rest is styled as before. Wide characters cut in half are
+// replaced with spaces.
+func cutLeft(s string, n int) string {
+	var (
+		b     strings.Builder
+		state byte
+		col   int
+	)
+	for len(s) > 0 {
+		seq, width, size, newState := ansi.DecodeSequence(s, state, nil)
+		state = newState
+		s = s[size:]
+
+		switch {
+		case width == 0 || col >= n:
+			b.WriteString(seq)
+		case col+width > n:
+			b.WriteString(strings.Repeat(" ", col+width-n))
+		}
+		col += width
+	}
+	return b.String()
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -28,6 +29,10 @@ type DefaultItemStyles struct {
 
 	// Characters matching the current filter, if any.
 	FilterMatch lipgloss.Style
+
+	// Indicators shown in front of the title when multi-select is enabled.
+	MarkedIndicator   lipgloss.Style
+	UnmarkedIndicator lipgloss.Style
 }
 
 // NewDefaultItemStyles returns style definitions for a default item. See
@@ -58,6 +63,14 @@ func NewDefaultItemStyles() (s DefaultItemStyles) {
 
 	s.FilterMatch = lipgloss.NewStyle().Underline(true)
 
+	s.MarkedIndicator = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#EE6FF8", Dark: "#EE6FF8"}).
+		SetString("◉")
+
+	s.UnmarkedIndicator = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"}).
+		SetString("○")
+
 	return s
 }
 
@@ -137,9 +150,8 @@ func (d DefaultDelegate) Update(msg tea.Msg, m *Model) tea.Cmd {
 // Render prints an item.
 func (d DefaultDelegate) Render(w io.Writer, m Model, index int, item Item) {
 	var (
-		title, desc  string
-		matchedRunes []int
-		s            = &d.Styles
+		title, desc string
+		s           = &d.Styles
 	)
 
 	if i, ok := item.(DefaultItem); ok {
@@ -154,19 +166,20 @@ func (d DefaultDelegate) Render(w io.Writer, m Model, index int, item Item) {
 		return
 	}
 
-	// Prevent text from exceeding list width
-	textwidth := m.width - s.NormalTitle.GetPaddingLeft() - s.NormalTitle.GetPaddingRight()
-	title = ansi.Truncate(title, textwidth, ellipsis)
-	if d.ShowDescription {
-		var lines []string
-		for i, line := range strings.Split(desc, "\n") {
-			if i >= d.height-1 {
-				break
-			}
-			lines = append(lines, ansi.Truncate(line, textwidth, ellipsis))
+	// In multi-select mode the title is preceded by a mark indicator and the
+	// description is indented to match. In tree mode both are preceded by
+	// guide lines.
+	var markPrefix, descPrefix string
+	if m.MultiSelect() {
+		if m.IsMarked(index) {
+			markPrefix = s.MarkedIndicator.String() + " "
+		} else {
+			markPrefix = s.UnmarkedIndicator.String() + " "
 		}
-		desc = strings.Join(lines, "\n")
+		descPrefix = strings.Repeat(" ", lipgloss.Width(markPrefix))
 	}
+	markPrefix = m.TreePrefix(index) + markPrefix
+	descPrefix = m.TreeIndent(index) + descPrefix
 
 	// Conditions
 	var (
@@ -175,34 +188,49 @@ func (d DefaultDelegate) Render(w io.Writer, m Model, index int, item Item) {
 		isFiltered  = m.FilterState() == Filtering || m.FilterState() == FilterApplied
 	)
 
-	if isFiltered && index < len(m.filteredItems) {
-		// Get indices of matched characters
-		matchedRunes = m.MatchesForItem(index)
-	}
-
+	titleStyle, descStyle := s.NormalTitle, s.NormalDesc
 	if emptyFilter {
-		title = s.DimmedTitle.Render(title)
-		desc = s.DimmedDesc.Render(desc)
+		titleStyle, descStyle = s.DimmedTitle, s.DimmedDesc
 	} else if isSelected && m.FilterState() != Filtering {
-		if isFiltered {
-			// Highlight matches
-			unmatched := s.SelectedTitle.Inline(true)
-			matched := unmatched.Inherit(s.FilterMatch)
-			title = lipgloss.StyleRunes(title, matchedRunes, matched, unmatched)
-		}
-		title = s.SelectedTitle.Render(title)
-		desc = s.SelectedDesc.Render(desc)
-	} else {
-		if isFiltered {
-			// Highlight matches
-			unmatched := s.NormalTitle.Inline(true)
-			matched := unmatched.Inherit(s.FilterMatch)
-			title = lipgloss.StyleRunes(title, matchedRunes, matched, unmatched)
+		titleStyle, descStyle = s.SelectedTitle, s.SelectedDesc
+	}
+
+	// Highlight matches before truncating, so that the indices of matched
+	// characters line up.
+	var descMatches []int
+	if isFiltered && !emptyFilter {
+		titleMatches := m.MatchesForItem(index)
+		descMatches = m.DescriptionMatchesForItem(index)
+		unmatched := titleStyle.Inline(true)
+		title = HighlightMatches(unmatched.Render(title), titleMatches, unmatched.Inherit(s.FilterMatch))
+	}
+
+	// Prevent text from exceeding list width
+	textwidth := m.width - s.NormalTitle.GetPaddingLeft() - s.NormalTitle.GetPaddingRight() - lipgloss.Width(markPrefix)
+	title = ansi.Truncate(title, textwidth, ellipsis)
+	if d.ShowDescription {
+		var (
+			lines  []string
+			offset int // rune index of the line in the description
+		)
+		for i, line := range strings.Split(desc, "\n") {
+			if i >= d.height-1 {
+				break
+			}
+			n := utf8.RuneCountInString(line)
+			if len(descMatches) > 0 {
+				unmatched := descStyle.Inline(true)
+				line = HighlightMatches(unmatched.Render(line), shiftIndexes(descMatches, -offset), unmatched.Inherit(s.FilterMatch))
+			}
+			offset += n + 1 // and the newline
+			lines = append(lines, descPrefix+ansi.Truncate(line, textwidth, ellipsis))
 		}
-		title = s.NormalTitle.Render(title)
-		desc = s.NormalDesc.Render(desc)
+		desc = strings.Join(lines, "\n")
 	}
 
+	title = titleStyle.Render(markPrefix + title)
+	desc = descStyle.Render(desc)
+
 	if d.ShowDescription {
 		fmt.Fprintf(w, "%s\n%s", title, desc) //nolint: errcheck
 		return
@@ -0,0 +1,151 @@
+package list
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// Names of the fields of a DefaultItem searched by DefaultItemFilter.
+// MultiFieldFilter also reports matches in fields with these names in
+// Rank.MatchedIndexes and Rank.DescriptionMatchedIndexes, which is where
+// delegates such as DefaultDelegate look for them.
+const (
+	TitleField       = "title"
+	DescriptionField = "description"
+)
+
+// SearchField is an item field searched by MultiFieldFilter.
+type SearchField struct {
+	// Name identifies the field in Rank.FieldMatchedIndexes.
+	Name string
+
+	// Value returns the text of the field for the given item.
+	Value func(Item) string
+
+	// Weight scales the scores of matches in the field, so that a match in
+	// a field with a higher weight ranks above an equally good match in a
+	// field with a lower weight. If 0 or less, the weight is 1.
+	Weight float64
+}
+
+// MultiFieldFilter returns an ItemFilterFunc that fuzzy-matches the words of
+// the filter term against the given fields of each item. An item matches if
+// each word matches at least one of its fields. Items are ranked by the sum
+// of the weighted score of the best match of each word, and the matches in
+// every field are reported in Rank.FieldMatchedIndexes. Matches in the
+// TitleField and DescriptionField are reported in Rank.MatchedIndexes and
+// Rank.DescriptionMatchedIndexes as well.
+func MultiFieldFilter(fields ...SearchField) ItemFilterFunc {
+	return func(term string, items []Item) []Rank {
+		words := strings.Fields(term)
+
+		// The text of each field of each item.
+		values := make([][]string, len(fields))
+		for f, field := range fields {
+			values[f] = make([]string, len(items))
+			for i, item := range items {
+				values[f][i] = field.Value(item)
+			}
+		}
+
+		var (
+			scores   = make([]float64, len(items))
+			matched  = make([]int, len(items)) // number of words matched
+			fieldIdx = make([]map[string][]int, len(items))
+		)
+		for w, word := range words {
+			best := make(map[int]float64)
+			for f, field := range fields {
+				for _, match := range fuzzy.Find(word, values[f]) {
+					i := match.Index
+					if fieldIdx[i] == nil {
+						fieldIdx[i] = make(map[string][]int)
+					}
+					indexes := byteToRuneIndexes(match.Str, match.MatchedIndexes)
+					fieldIdx[i][field.Name] = append(fieldIdx[i][field.Name], indexes...)
+
+					s := weighScore(match.Score, field.Weight)
+					if b, ok := best[i]; !ok || s > b {
+						best[i] = s
+					}
+				}
+			}
+			for i, s := range best {
+				if matched[i] == w {
+					scores[i] += s
+					matched[i]++
+				}
+			}
+		}
+
+		var ranks []Rank
+		for i := range items {
+			if matched[i] < len(words) {
+				continue
+			}
+			fieldMatches := make(map[string][]int, len(fieldIdx[i]))
+			for name, indexes := range fieldIdx[i] {
+				fieldMatches[name] = uniqueSorted(indexes)
+			}
+			ranks = append(ranks, Rank{
+				Index:                     i,
+				MatchedIndexes:            fieldMatches[TitleField],
+				DescriptionMatchedIndexes: fieldMatches[DescriptionField],
+				FieldMatchedIndexes:       fieldMatches,
+				Score:                     scores[i],
+			})
+		}
+
+		sort.SliceStable(ranks, func(i, j int) bool {
+			return ranks[i].Score > ranks[j].Score
+		})
+		return ranks
+	}
+}
+
+// defaultItemFilter searches the title and description of DefaultItems.
+var defaultItemFilter = MultiFieldFilter(
+	SearchField{Name: TitleField, Value: itemTitle, Weight: 1},
+	SearchField{Name: DescriptionField, Value: itemDescription, Weight: 0.5}, //nolint:gomnd
+)
+
+// DefaultItemFilter is an ItemFilterFunc that searches the titles and
+// descriptions of DefaultItems, with matches in titles ranking above those
+// in descriptions. Items that aren't DefaultItems are searched by their
+// FilterValue as if it were their title. DefaultDelegate highlights matches
+// in both. See MultiFieldFilter.
+func DefaultItemFilter(term string, items []Item) []Rank {
+	return defaultItemFilter(term, items)
+}
+
+// itemTitle returns the title of a DefaultItem, or the FilterValue of other
+// items.
+func itemTitle(item Item) string {
+	if i, ok := item.(DefaultItem); ok {
+		return i.Title()
+	}
+	return item.FilterValue()
+}
+
+// itemDescription returns the description of a DefaultItem.
+func itemDescription(item Item) string {
+	if i, ok := item.(DefaultItem); ok {
+		return i.Description()
+	}
+	return ""
+}
+
+// weighScore scales a fuzzy match score by the given weight. Scores can be
+// negative, in which case they're divided instead so that a higher weight
+// still means a higher score.
+func weighScore(score int, weight float64) float64 {
+	if weight <= 0 {
+		weight = 1
+	}
+	if score < 0 {
+		return float64(score) / weight
+	}
+	return float64(score) * weight
+}
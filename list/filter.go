@@ -0,0 +1,180 @@
+package list
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// filterDebounceMsg starts a filter run once the filter input has been idle
+// for FilterDebounce.
+type filterDebounceMsg struct {
+	id  int
+	gen int
+}
+
+// filterResultMsg contains matches from a filter run. Runs are tagged with a
+// generation so that results from superseded runs can be dropped.
+type filterResultMsg struct {
+	id      int
+	gen     int
+	matches filteredItems
+	first   bool    // whether these are the first matches of the run
+	next    tea.Cmd // filters the next chunk, if any
+}
+
+// requestFilter starts a new filter run in response to the filter input
+// changing. If FilterDebounce is set the run is delayed until the input has
+// been idle for that long.
+func (m *Model) requestFilter() tea.Cmd {
+	if m.FilterDebounce <= 0 || m.FilterInput.Value() == "" {
+		return m.filterCmd()
+	}
+
+	m.cancelFilter()
+	m.filterPending = true
+	id, gen := m.id, m.filterGen
+	return tea.Tick(m.FilterDebounce, func(time.Time) tea.Msg {
+		return filterDebounceMsg{id: id, gen: gen}
+	})
+}
+
+// filterCmd starts a new filter run, superseding any previous one, and
+// returns a command that delivers its results. If FilterChunkSize is set the
+// items are filtered in chunks and matches are delivered as they're found.
+//
+// The run filters a copy of the items, since the list's own slice is changed
+// in place when items are inserted, moved or removed.
+func (m *Model) filterCmd() tea.Cmd {
+	m.cancelFilter()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.filterCancel = cancel
+
+	id, gen, term := m.id, m.filterGen, m.FilterInput.Value()
+	if term == "" || m.filterState == Unfiltered {
+		matches := m.itemsAsFilterItems()
+		return func() tea.Msg {
+			return filterResultMsg{id: id, gen: gen, matches: matches, first: true}
+		}
+	}
+	items := append([]Item(nil), m.items...)
+	return filterChunk(ctx, id, gen, m.itemFilter(), term, items, 0, m.FilterChunkSize)
+}
+
+// filterNow filters the items with the current filter right away, superseding
+// any filter run.
+func (m *Model) filterNow() {
+	m.cancelFilter()
+	if term := m.FilterInput.Value(); term == "" {
+		m.filteredItems = m.itemsAsFilterItems()
+	} else {
+		m.filteredItems = matchItems(m.itemFilter(), term, m.items, 0)
+	}
+	m.sortFilteredItems()
+}
+
+// cancelFilter cancels the running filter run, if any, and makes sure that
+// results still on their way are dropped.
+func (m *Model) cancelFilter() {
+	m.filterGen++
+	m.filterPending = false
+	m.selectAfterFilter = -1
+	if m.filterCancel != nil {
+		m.filterCancel()
+		m.filterCancel = nil
+	}
+}
+
+// handleFilterResult applies matches from the current filter run.
+func (m *Model) handleFilterResult(msg filterResultMsg) tea.Cmd {
+	if msg.first {
+		m.filteredItems = msg.matches
+	} else {
+		// Matches from this chunk are ranked together with the ones
+		// already found by their scores, and after them for equal scores.
+		for i := range msg.matches {
+			msg.matches[i].rank += len(m.filteredItems)
+		}
+		m.filteredItems = append(m.filteredItems, msg.matches...)
+	}
+	m.sortFilteredItems()
+	m.updatePagination()
+	if msg.next == nil {
+		m.filterCancel = nil
+		if m.selectAfterFilter >= 0 {
+			m.selectGlobal(m.selectAfterFilter)
+			m.selectAfterFilter = -1
+		}
+	}
+	return msg.next
+}
+
+// filterChunk returns a command that filters size items starting at offset.
+// If size is 0 or less all remaining items are filtered at once.
+func filterChunk(ctx context.Context, id, gen int, filter ItemFilterFunc, term string, items []Item, offset, size int) tea.Cmd {
+	return func() tea.Msg {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		end := len(items)
+		if size > 0 && offset+size < end {
+			end = offset + size
+		}
+
+		msg := filterResultMsg{
+			id:      id,
+			gen:     gen,
+			matches: matchItems(filter, term, items[offset:end], offset),
+			first:   offset == 0,
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if end < len(items) {
+			msg.next = filterChunk(ctx, id, gen, filter, term, items, end, size)
+		}
+		return msg
+	}
+}
+
+// itemFilter returns the ItemFilter, or Filter adapted to an ItemFilterFunc
+// if it's not set.
+func (m Model) itemFilter() ItemFilterFunc {
+	if m.ItemFilter != nil {
+		return m.ItemFilter
+	}
+	return filterValues(m.Filter)
+}
+
+// filterValues adapts a FilterFunc to an ItemFilterFunc by filtering the
+// FilterValue of each item.
+func filterValues(filter FilterFunc) ItemFilterFunc {
+	return func(term string, items []Item) []Rank {
+		targets := make([]string, len(items))
+		for i, t := range items {
+			targets[i] = t.FilterValue()
+		}
+		return filter(term, targets)
+	}
+}
+
+// matchItems runs the filter over the given items, which start at offset in
+// the unfiltered list of items.
+func matchItems(filter ItemFilterFunc, term string, items []Item, offset int) filteredItems {
+	matches := filteredItems{}
+	for i, r := range filter(term, items) {
+		matches = append(matches, filteredItem{
+			index:        offset + r.Index,
+			item:         items[r.Index],
+			matches:      r.MatchedIndexes,
+			descMatches:  r.DescriptionMatchedIndexes,
+			fieldMatches: r.FieldMatchedIndexes,
+			rank:         i,
+			score:        r.Score,
+		})
+	}
+	return matches
+}
@@ -0,0 +1,319 @@
+package list
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFrecencyWeight   = 10
+	defaultFrecencyHalfLife = 7 * 24 * time.Hour
+)
+
+// HistoryEntry records how often and when an item was last chosen.
+type HistoryEntry struct {
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+// HistoryStore persists the selection history used to rank items by
+// frecency. Entries are keyed by Frecency.Key.
+type HistoryStore interface {
+	// Load returns the history recorded so far.
+	Load() (map[string]HistoryEntry, error)
+
+	// Record notes that the item with the given key was chosen at the given
+	// time. It's called from Update, so it should be quick.
+	Record(key string, at time.Time) error
+}
+
+// Frecency ranks items by how often and how recently they were chosen, and
+// blends that ranking with the scores of filter matches. See SetFrecency.
+//
+// A Frecency is safe to share between lists.
+type Frecency struct {
+	// Weight is how much a choice made just now adds to the score of a
+	// filter match, before decay. DefaultFilter scores are in the tens for
+	// a good match. By default this is 10.
+	Weight float64
+
+	// HalfLife is how long it takes for a choice to count half as much. By
+	// default this is a week.
+	HalfLife time.Duration
+
+	// Key identifies items in the history. By default items are identified
+	// by their FilterValue.
+	Key func(Item) string
+
+	mu      sync.Mutex
+	store   HistoryStore
+	history map[string]HistoryEntry
+}
+
+// NewFrecency returns a Frecency that keeps its history in the given store,
+// loading what's been recorded so far. If store is nil the history is only
+// kept in memory.
+func NewFrecency(store HistoryStore) (*Frecency, error) {
+	if store == nil {
+		store = NewMemoryHistory()
+	}
+	history, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if history == nil {
+		history = make(map[string]HistoryEntry)
+	}
+
+	return &Frecency{
+		Weight:   defaultFrecencyWeight,
+		HalfLife: defaultFrecencyHalfLife,
+		store:    store,
+		history:  history,
+	}, nil
+}
+
+// Record records the given item as chosen just now. The choice counts
+// towards the item's score even if the store fails to persist it.
+func (f *Frecency) Record(item Item) error {
+	key, now := f.key(item), time.Now()
+
+	f.mu.Lock()
+	f.history[key] = recordEntry(f.history[key], now)
+	f.mu.Unlock()
+
+	return f.store.Record(key, now)
+}
+
+// Score returns the frecency score of the given item: the number of times
+// it was chosen, decayed by the time since it was last chosen. Items never
+// chosen score 0.
+func (f *Frecency) Score(item Item) float64 {
+	return f.scoreAt(item, time.Now())
+}
+
+// scoreAt returns the frecency score of the given item at the given time.
+func (f *Frecency) scoreAt(item Item, now time.Time) float64 {
+	e, ok := f.entry(item)
+	if !ok {
+		return 0
+	}
+
+	if f.HalfLife <= 0 {
+		return float64(e.Count)
+	}
+	age := math.Max(0, float64(now.Sub(e.Last)))
+	return float64(e.Count) * math.Exp2(-age/float64(f.HalfLife))
+}
+
+// SortOrder returns a sort order listing the items with the highest
+// frecency scores first, for ranking items by frecency when the list isn't
+// filtered. See SetSortOrders.
+func (f *Frecency) SortOrder() SortOrder {
+	return SortOrder{
+		Name: "frecent",
+		Less: func(a, b Item) bool {
+			return f.sortKey(a) > f.sortKey(b)
+		},
+	}
+}
+
+// sortKey returns a key that orders items as their scores do, without
+// depending on the current time: as every score decays at the same rate,
+// the order of two scores never changes as time passes. Items never chosen
+// come last.
+func (f *Frecency) sortKey(item Item) float64 {
+	e, ok := f.entry(item)
+	if !ok || e.Count <= 0 {
+		return math.Inf(-1)
+	}
+	if f.HalfLife <= 0 {
+		return float64(e.Count)
+	}
+
+	// log2 of Count * 2^(-(now-Last)/HalfLife), plus now/HalfLife.
+	return math.Log2(float64(e.Count)) + float64(e.Last.UnixNano())/float64(f.HalfLife)
+}
+
+// entry returns the history entry of the given item.
+func (f *Frecency) entry(item Item) (HistoryEntry, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e, ok := f.history[f.key(item)]
+	return e, ok
+}
+
+// key returns the key of the given item in the history.
+func (f *Frecency) key(item Item) string {
+	if f.Key != nil {
+		return f.Key(item)
+	}
+	return item.FilterValue()
+}
+
+// SetFrecency sets the Frecency used to rank filter matches, blending how
+// often and how recently items were chosen with how well they match: matches
+// are ranked by their Rank.Score plus Weight times their frecency score.
+// Filters that don't score their matches are thus ranked by frecency,
+// keeping their own order for items with the same score. Call
+// RecordSelection when the user chooses an item. Pass nil to rank matches
+// by the filter alone.
+func (m *Model) SetFrecency(f *Frecency) {
+	m.frecency = f
+}
+
+// Frecency returns the Frecency used to rank filter matches, if any.
+func (m Model) Frecency() *Frecency {
+	return m.frecency
+}
+
+// RecordSelection records the selected item as chosen in the history of the
+// Frecency, so that it ranks higher from now on. If the list is sorted, it's
+// sorted again, keeping the selected item selected. It does nothing if no
+// Frecency is set.
+func (m *Model) RecordSelection() error {
+	item := m.SelectedItem()
+	if m.frecency == nil || item == nil {
+		return nil
+	}
+	err := m.frecency.Record(item)
+
+	if m.sortLess() != nil {
+		selected := m.GlobalIndex()
+		m.resort()
+		if m.filterState != Unfiltered {
+			m.sortFilteredItems()
+		}
+		m.updatePagination()
+		m.selectGlobal(selected)
+	}
+	return err
+}
+
+// MemoryHistory is a HistoryStore that keeps the history in memory only.
+type MemoryHistory struct {
+	mu      sync.Mutex
+	entries map[string]HistoryEntry
+}
+
+// NewMemoryHistory returns an empty MemoryHistory.
+func NewMemoryHistory() *MemoryHistory {
+	return &MemoryHistory{entries: make(map[string]HistoryEntry)}
+}
+
+// Load returns a copy of the recorded history.
+func (h *MemoryHistory) Load() (map[string]HistoryEntry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return copyHistory(h.entries), nil
+}
+
+// Record notes that the item with the given key was chosen at the given
+// time.
+func (h *MemoryHistory) Record(key string, at time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries[key] = recordEntry(h.entries[key], at)
+	return nil
+}
+
+// FileHistory is a HistoryStore that keeps the history in a JSON file. The
+// file is rewritten on every choice, so it suits histories of up to a few
+// thousand items.
+type FileHistory struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]HistoryEntry // nil until loaded
+}
+
+// NewFileHistory returns a FileHistory stored at the given path. The file
+// and its directory are created when the first choice is recorded.
+func NewFileHistory(path string) *FileHistory {
+	return &FileHistory{path: path}
+}
+
+// Load reads the history from the file. A missing file is an empty history.
+func (h *FileHistory) Load() (map[string]HistoryEntry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.load(); err != nil {
+		return nil, err
+	}
+	return copyHistory(h.entries), nil
+}
+
+// Record notes that the item with the given key was chosen at the given
+// time, and writes the history to the file.
+func (h *FileHistory) Record(key string, at time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.load(); err != nil {
+		return err
+	}
+	h.entries[key] = recordEntry(h.entries[key], at)
+
+	b, err := json.Marshal(h.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil { //nolint:gomnd
+		return err
+	}
+
+	// Write to a temporary file first so that the history isn't lost if
+	// writing fails halfway.
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil { //nolint:gomnd
+		return err
+	}
+	return os.Rename(tmp, h.path)
+}
+
+// load reads the history from the file, unless it's been read already.
+func (h *FileHistory) load() error {
+	if h.entries != nil {
+		return nil
+	}
+
+	entries := make(map[string]HistoryEntry)
+	b, err := os.ReadFile(h.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(b, &entries); err != nil {
+			return err
+		}
+	}
+	if entries == nil {
+		entries = make(map[string]HistoryEntry)
+	}
+	h.entries = entries
+	return nil
+}
+
+// recordEntry returns the given entry with a choice at the given time added.
+func recordEntry(e HistoryEntry, at time.Time) HistoryEntry {
+	e.Count++
+	if at.After(e.Last) {
+		e.Last = at
+	}
+	return e
+}
+
+// copyHistory returns a copy of the given history.
+func copyHistory(history map[string]HistoryEntry) map[string]HistoryEntry {
+	c := make(map[string]HistoryEntry, len(history))
+	for k, v := range history {
+		c[k] = v
+	}
+	return c
+}
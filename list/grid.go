@@ -0,0 +1,175 @@
+package list
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// SetGridLayout lays items out in a grid of cells of the given width, as
+// many to a row as fit in the width of the list, instead of one below the
+// other. Each cell is as tall as the delegate's Height, and delegates render
+// items to fit the width of a cell. Pages hold as many rows of cells as fit
+// in the height of the list. The cursor keys move through the grid in two
+// dimensions; see KeyMap.CursorLeft and KeyMap.CursorRight.
+//
+// Items aren't grouped in grid layout, and scroll mode is turned off. A cell
+// width of 0 or less lists items one below the other again.
+func (m *Model) SetGridLayout(cellWidth int) {
+	cellWidth = max(0, cellWidth)
+	if cellWidth == m.gridCellWidth {
+		return
+	}
+	if cellWidth > 0 && m.scrollMode {
+		m.SetScrollMode(false)
+	}
+
+	selected := m.GlobalIndex()
+	m.gridCellWidth = cellWidth
+	m.updatePagination()
+	m.selectGlobal(selected)
+	m.updateKeybindings()
+}
+
+// GridCellWidth returns the width of a cell in grid layout, or 0 if items
+// are listed one below the other.
+func (m Model) GridCellWidth() int {
+	return m.gridCellWidth
+}
+
+// GridColumns returns the number of cells in a row in grid layout, or 1 if
+// items are listed one below the other.
+func (m Model) GridColumns() int {
+	if m.gridCellWidth <= 0 {
+		return 1
+	}
+	return max(1, m.width/m.gridCellWidth)
+}
+
+// CursorLeft moves the cursor to the previous item. In grid layout this is
+// the cell to the left, or the last cell of the row above.
+func (m *Model) CursorLeft() {
+	if m.gridCellWidth <= 0 {
+		m.CursorUp()
+		return
+	}
+	m.moveInGrid(-1)
+}
+
+// CursorRight moves the cursor to the next item. In grid layout this is the
+// cell to the right, or the first cell of the row below.
+func (m *Model) CursorRight() {
+	if m.gridCellWidth <= 0 {
+		m.CursorDown()
+		return
+	}
+	m.moveInGrid(1)
+}
+
+// moveInGrid moves the cursor by the given number of cells, going to the
+// page the cell is on. Moving down past the end of the items goes to the
+// last item when it's on a lower row. With InfiniteScrolling, moving past
+// either end wraps around to the other.
+func (m *Model) moveInGrid(delta int) {
+	n := m.visibleCount()
+	if n == 0 {
+		return
+	}
+
+	index := m.Index() + delta
+	switch {
+	case index < 0:
+		if !m.InfiniteScrolling {
+			return
+		}
+		index = n - 1
+
+	case index >= n:
+		cols := m.GridColumns()
+		switch {
+		case m.InfiniteScrolling:
+			index = 0
+		case (n-1)/cols > m.Index()/cols:
+			index = n - 1
+		default:
+			return
+		}
+	}
+	m.Select(index)
+}
+
+// gridView renders the given range of items as rows of cells.
+func (m Model) gridView(items []Item, start, end int) string {
+	var (
+		cols   = m.GridColumns()
+		height = m.delegate.Height()
+		rows   []string
+	)
+
+	// Delegates lay items out to fit the width of the list, so give them
+	// the width of a cell.
+	cell := m
+	cell.width = m.gridCellWidth
+
+	for i := start; i < end; i += cols {
+		var row []string
+		for j := i; j < min(i+cols, end); j++ {
+			var b strings.Builder
+			m.delegate.Render(&b, cell, j, items[j])
+			row = append(row, fitCell(b.String(), m.gridCellWidth, height))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+	}
+	return strings.Join(rows, strings.Repeat("\n", m.delegate.Spacing()+1))
+}
+
+// fitCell truncates or pads each line of s to the given width, and drops or
+// adds lines to make it the given height.
+func fitCell(s string, width, height int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+
+	for i, line := range lines {
+		line = ansi.Truncate(line, width, ellipsis)
+		lines[i] = line + strings.Repeat(" ", max(0, width-ansi.StringWidth(line)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pageHelp returns the given page keybinding with the keys it shares with
+// CursorLeft and CursorRight left out of its help in grid layout, where
+// those keys move the cursor instead.
+func (m Model) pageHelp(b key.Binding) key.Binding {
+	if m.gridCellWidth <= 0 {
+		return b
+	}
+
+	shared := make(map[string]bool)
+	for _, c := range []key.Binding{m.KeyMap.CursorLeft, m.KeyMap.CursorRight} {
+		for _, k := range strings.Split(c.Help().Key, "/") {
+			shared[k] = true
+		}
+	}
+
+	var keys []string
+	for _, k := range strings.Split(b.Help().Key, "/") {
+		if !shared[k] {
+			keys = append(keys, k)
+		}
+	}
+	b.SetHelp(strings.Join(keys, "/"), b.Help().Desc)
+	return b
+}
+
+// gridColumnOf returns the column of the cell of the visible item at the
+// given index in grid layout. Pages start at the beginning of a row.
+func (m Model) gridColumnOf(index int) int {
+	return index % m.GridColumns()
+}
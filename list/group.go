@@ -0,0 +1,247 @@
+package list
+
+import "fmt"
+
+// GroupedItem is an item that belongs to a named group. When any items in
+// the list implement GroupedItem, items are listed by group, each under a
+// section header, except in tree mode and grid layout. Groups are listed in
+// the order their first item appears, so they follow the active sort order
+// or the filter's ranking. Items that don't implement GroupedItem are listed
+// together without a header.
+type GroupedItem interface {
+	Item
+
+	// Group returns the name of the group the item belongs to.
+	Group() string
+}
+
+// groupSection is a group of visible items. Collapsed sections contain no
+// items, so their start and end are the same.
+type groupSection struct {
+	name       string
+	start, end int // range in the grouped items
+	size       int // number of visible items in the group, collapsed or not
+	collapsed  bool
+}
+
+// SetGroupCollapsed collapses or expands the named group. The items of a
+// collapsed group are hidden, but its header is still shown.
+func (m *Model) SetGroupCollapsed(name string, collapsed bool) {
+	if collapsed == m.collapsedGroups[name] {
+		return
+	}
+
+	var selected int
+	hasSelection := m.visibleCount() > 0
+	if hasSelection {
+		selected = m.GlobalIndex()
+	}
+
+	if collapsed {
+		if m.collapsedGroups == nil {
+			m.collapsedGroups = make(map[string]bool)
+		}
+		m.collapsedGroups[name] = true
+	} else {
+		delete(m.collapsedGroups, name)
+	}
+
+	m.updatePagination()
+	if hasSelection {
+		m.selectGlobal(selected)
+	}
+	m.updateKeybindings()
+}
+
+// GroupCollapsed returns whether the named group is collapsed.
+func (m Model) GroupCollapsed(name string) bool {
+	return m.collapsedGroups[name]
+}
+
+// CollapseGroup collapses the group of the selected item and selects the
+// first item after it.
+func (m *Model) CollapseGroup() {
+	i := m.Index()
+	if m.sections == nil || i >= len(m.sectionOf) {
+		return
+	}
+	s := m.sections[m.sectionOf[i]]
+	if s.name == "" {
+		return
+	}
+
+	m.SetGroupCollapsed(s.name, true)
+	m.Select(min(s.start, max(0, m.visibleCount()-1)))
+}
+
+// ToggleGroup expands the collapsed group whose header is next to the
+// selected item, preferring the one above it, and selects its first item.
+// If there's no such group it collapses the group of the selected item, as
+// CollapseGroup does. Collapsed groups have no items to select, so this is
+// how a single group is expanded again from the keyboard.
+func (m *Model) ToggleGroup() {
+	s, ok := m.adjacentCollapsedGroup()
+	if !ok {
+		m.CollapseGroup()
+		return
+	}
+
+	m.SetGroupCollapsed(s.name, false)
+	for i, sec := range m.sections {
+		if sec.name == s.name {
+			m.Select(m.sections[i].start)
+			return
+		}
+	}
+}
+
+// adjacentCollapsedGroup returns the collapsed group whose header is
+// rendered right above the selected item or, if there's none, right below
+// it.
+func (m Model) adjacentCollapsedGroup() (groupSection, bool) {
+	i := m.Index()
+	if len(m.groupedItems) == 0 {
+		i = 0
+	}
+
+	// Collapsed groups have no items, so their headers are rendered above
+	// the item at their start, and the nearest one above comes last.
+	for j := len(m.sections) - 1; j >= 0; j-- {
+		if s := m.sections[j]; s.collapsed && s.start == i {
+			return s, true
+		}
+	}
+	for _, s := range m.sections {
+		if s.collapsed && s.start == i+1 && i+1 <= len(m.groupedItems) {
+			return s, true
+		}
+	}
+	return groupSection{}, false
+}
+
+// ExpandAllGroups expands all collapsed groups.
+func (m *Model) ExpandAllGroups() {
+	if len(m.collapsedGroups) == 0 {
+		return
+	}
+
+	var selected int
+	hasSelection := m.visibleCount() > 0
+	if hasSelection {
+		selected = m.GlobalIndex()
+	}
+
+	m.collapsedGroups = nil
+	m.updatePagination()
+	if hasSelection {
+		m.selectGlobal(selected)
+	}
+	m.updateKeybindings()
+}
+
+// regroup arranges the visible items into groups. Groups without visible
+// items, such as ones with no items matching the filter, are left out.
+func (m *Model) regroup() {
+	m.groupedItems, m.sections, m.sectionOf = nil, nil, nil
+	if m.tree != nil || m.gridCellWidth > 0 || !hasGroupedItems(m.items) {
+		return
+	}
+
+	items := m.ungroupedItems()
+	if items == nil && m.filterState == Unfiltered {
+		items = m.itemsAsFilterItems()
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	var names []string
+	groups := make(map[string]filteredItems)
+	for _, fi := range items {
+		name := groupOf(fi.item)
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], fi)
+	}
+
+	grouped := make(filteredItems, 0, len(items))
+	sectionOf := make([]int, 0, len(items))
+	sections := make([]groupSection, len(names))
+	for i, name := range names {
+		s := groupSection{
+			name:      name,
+			start:     len(grouped),
+			size:      len(groups[name]),
+			collapsed: name != "" && m.collapsedGroups[name],
+		}
+		if !s.collapsed {
+			grouped = append(grouped, groups[name]...)
+			for range groups[name] {
+				sectionOf = append(sectionOf, i)
+			}
+		}
+		s.end = len(grouped)
+		sections[i] = s
+	}
+
+	m.groupedItems = grouped
+	m.sections = sections
+	m.sectionOf = sectionOf
+}
+
+// headersBefore returns the sections whose headers are rendered above the
+// grouped item at the given index on a page starting at pageStart. Groups
+// continuing from the previous page repeat their header.
+func (m Model) headersBefore(index, pageStart int) []groupSection {
+	sec := m.sectionOf[index]
+
+	// Collapsed groups have no items, so their headers go above the first
+	// item that follows them.
+	first := sec
+	for first > 0 && m.sections[first-1].collapsed && m.sections[first-1].start == index {
+		first--
+	}
+	headers := append([]groupSection{}, m.sections[first:sec]...)
+
+	if s := m.sections[sec]; s.name != "" && (s.start == index || index == pageStart) {
+		headers = append(headers, s)
+	}
+	return headers
+}
+
+// trailingSections returns the collapsed sections after the last grouped
+// item, whose headers are rendered at the end of the last page.
+func (m Model) trailingSections() []groupSection {
+	first := len(m.sections)
+	for first > 0 && m.sections[first-1].collapsed && m.sections[first-1].start == len(m.groupedItems) {
+		first--
+	}
+	return m.sections[first:]
+}
+
+// groupHeaderView renders the header of a section.
+func (m Model) groupHeaderView(s groupSection) string {
+	icon := m.Styles.GroupExpandedIcon.String()
+	if s.collapsed {
+		icon = m.Styles.GroupCollapsedIcon.String()
+	}
+	return m.Styles.GroupHeader.Render(icon+" "+s.name) +
+		" " + m.Styles.GroupCount.Render(fmt.Sprint(s.size))
+}
+
+func groupOf(item Item) string {
+	if g, ok := item.(GroupedItem); ok {
+		return g.Group()
+	}
+	return ""
+}
+
+func hasGroupedItems(items []Item) bool {
+	for _, item := range items {
+		if _, ok := item.(GroupedItem); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package list
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// HighlightMatches renders the runes of s at the given indexes with the
+// given style, such as the matches returned by MatchesForItem. Indexes
+// count the runes of the text without its escape sequences, so s may
+// already be styled; the rest of the text keeps its styling. Characters
+// made of several runes, such as emoji with modifiers, are highlighted
+// whole if any of their runes match. The style is applied inline.
+func HighlightMatches(s string, matches []int, style lipgloss.Style) string {
+	if len(matches) == 0 {
+		return s
+	}
+
+	matched := make(map[int]bool, len(matches))
+	for _, i := range matches {
+		matched[i] = true
+	}
+	style = style.Inline(true)
+
+	var (
+		b      strings.Builder
+		run    strings.Builder // matched text waiting to be styled
+		active string          // SGR sequences in effect, restored after a run
+		state  byte
+		pos    int // rune index in the text
+	)
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		b.WriteString(style.Render(run.String()))
+		b.WriteString(active)
+		run.Reset()
+	}
+
+	for len(s) > 0 {
+		seq, _, n, newState := ansi.DecodeSequence(s, state, nil)
+		state = newState
+		s = s[n:]
+
+		switch {
+		case seq == "":
+			continue
+
+		case seq[0] == ansi.ESC || (seq[0] >= 0x80 && seq[0] < 0xA0):
+			// An escape sequence. Keep track of the styling in effect so
+			// that it can be restored after the next highlighted run.
+			flush()
+			b.WriteString(seq)
+			if ansi.HasCsiPrefix(seq) && strings.HasSuffix(seq, "m") {
+				if seq == "\x1b[0m" || seq == "\x1b[m" {
+					active = ""
+				} else {
+					active += seq
+				}
+			}
+
+		case seq[0] < ' ' || seq[0] == ansi.DEL:
+			// Control characters such as newlines are never styled.
+			flush()
+			b.WriteString(seq)
+			pos++
+
+		default:
+			hit := false
+			for i := utf8.RuneCountInString(seq); i > 0; i-- {
+				hit = hit || matched[pos]
+				pos++
+			}
+			if hit {
+				run.WriteString(seq)
+			} else {
+				flush()
+				b.WriteString(seq)
+			}
+		}
+	}
+	flush()
+	return b.String()
+}
+
+// shiftIndexes returns the given indexes with n added to each.
+func shiftIndexes(indexes []int, n int) []int {
+	shifted := make([]int, len(indexes))
+	for i, v := range indexes {
+		shifted[i] = v + n
+	}
+	return shifted
+}
@@ -15,6 +15,57 @@ type KeyMap struct {
 	Filter      key.Binding
 	ClearFilter key.Binding
 
+	// Keybindings for moving the cursor sideways in grid layout. They take
+	// precedence over the page keybindings they share keys with.
+	CursorLeft  key.Binding
+	CursorRight key.Binding
+
+	// Keybindings used for marking items when multi-select is enabled.
+	ToggleMark    key.Binding
+	MarkAll       key.Binding
+	UnmarkAll     key.Binding
+	MarkRangeUp   key.Binding
+	MarkRangeDown key.Binding
+
+	// Keybindings for moving the selected item when reordering is enabled.
+	MoveUp       key.Binding
+	MoveDown     key.Binding
+	MoveToTop    key.Binding
+	MoveToBottom key.Binding
+
+	// Keybindings for navigating nodes in tree mode.
+	ExpandNode   key.Binding
+	CollapseNode key.Binding
+	GoToParent   key.Binding
+
+	// Keybindings for scrolling the preview pane, when it's shown.
+	PreviewUp           key.Binding
+	PreviewDown         key.Binding
+	PreviewHalfPageUp   key.Binding
+	PreviewHalfPageDown key.Binding
+
+	// Keybindings for the menu of actions for the selected item, when it has
+	// any. The cursor keybindings move through the menu while it's open.
+	OpenActionMenu  key.Binding
+	ChooseAction    key.Binding
+	CloseActionMenu key.Binding
+
+	// Keybindings for removing the selected item, when removing is enabled,
+	// and for answering the confirmation prompt.
+	RemoveItem    key.Binding
+	UndoRemove    key.Binding
+	ConfirmRemove key.Binding
+	CancelRemove  key.Binding
+
+	// Keybinding for cycling through the sort orders, if there are any.
+	CycleSortOrder key.Binding
+
+	// Keybindings for collapsing and expanding groups, when items are
+	// grouped.
+	CollapseGroup key.Binding
+	ToggleGroup   key.Binding
+	ExpandGroups  key.Binding
+
 	// Keybindings used when setting a filter.
 	CancelWhileFiltering key.Binding
 	AcceptWhileFiltering key.Binding
@@ -50,6 +101,14 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("right", "l", "pgdown", "f", "d"),
 			key.WithHelp("→/l/pgdn", "next page"),
 		),
+		CursorLeft: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("←/h", "left"),
+		),
+		CursorRight: key.NewBinding(
+			key.WithKeys("right", "l"),
+			key.WithHelp("→/l", "right"),
+		),
 		GoToStart: key.NewBinding(
 			key.WithKeys("home", "g"),
 			key.WithHelp("g/home", "go to start"),
@@ -67,6 +126,130 @@ func DefaultKeyMap() KeyMap {
 			key.WithHelp("esc", "clear filter"),
 		),
 
+		// Marking.
+		ToggleMark: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "mark"),
+		),
+		MarkAll: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "mark all"),
+		),
+		UnmarkAll: key.NewBinding(
+			key.WithKeys("U"),
+			key.WithHelp("U", "unmark all"),
+		),
+		MarkRangeUp: key.NewBinding(
+			key.WithKeys("shift+up", "K"),
+			key.WithHelp("shift+↑/K", "mark up"),
+		),
+		MarkRangeDown: key.NewBinding(
+			key.WithKeys("shift+down", "J"),
+			key.WithHelp("shift+↓/J", "mark down"),
+		),
+
+		// Reordering.
+		MoveUp: key.NewBinding(
+			key.WithKeys("alt+up", "alt+k"),
+			key.WithHelp("alt+↑/k", "move up"),
+		),
+		MoveDown: key.NewBinding(
+			key.WithKeys("alt+down", "alt+j"),
+			key.WithHelp("alt+↓/j", "move down"),
+		),
+		MoveToTop: key.NewBinding(
+			key.WithKeys("alt+home", "alt+g"),
+			key.WithHelp("alt+g/home", "move to top"),
+		),
+		MoveToBottom: key.NewBinding(
+			key.WithKeys("alt+end", "alt+G"),
+			key.WithHelp("alt+G/end", "move to bottom"),
+		),
+
+		// Tree navigation.
+		ExpandNode: key.NewBinding(
+			key.WithKeys("shift+right", "L"),
+			key.WithHelp("L", "expand"),
+		),
+		CollapseNode: key.NewBinding(
+			key.WithKeys("shift+left", "H"),
+			key.WithHelp("H", "collapse"),
+		),
+		GoToParent: key.NewBinding(
+			key.WithKeys("backspace", "p"),
+			key.WithHelp("p", "go to parent"),
+		),
+
+		// Preview.
+		PreviewUp: key.NewBinding(
+			key.WithKeys("ctrl+up", "ctrl+y"),
+			key.WithHelp("ctrl+y", "preview up"),
+		),
+		PreviewDown: key.NewBinding(
+			key.WithKeys("ctrl+down", "ctrl+e"),
+			key.WithHelp("ctrl+e", "preview down"),
+		),
+		PreviewHalfPageUp: key.NewBinding(
+			key.WithKeys("ctrl+u"),
+			key.WithHelp("ctrl+u", "preview ½ page up"),
+		),
+		PreviewHalfPageDown: key.NewBinding(
+			key.WithKeys("ctrl+d"),
+			key.WithHelp("ctrl+d", "preview ½ page down"),
+		),
+
+		// Actions.
+		OpenActionMenu: key.NewBinding(
+			key.WithKeys("."),
+			key.WithHelp(".", "actions"),
+		),
+		ChooseAction: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "choose"),
+		),
+		CloseActionMenu: key.NewBinding(
+			key.WithKeys("esc", "."),
+			key.WithHelp("esc", "close"),
+		),
+
+		// Removing.
+		RemoveItem: key.NewBinding(
+			key.WithKeys("x", "delete"),
+			key.WithHelp("x", "remove"),
+		),
+		UndoRemove: key.NewBinding(
+			key.WithKeys("ctrl+z"),
+			key.WithHelp("ctrl+z", "undo"),
+		),
+		ConfirmRemove: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "remove"),
+		),
+		CancelRemove: key.NewBinding(
+			key.WithKeys("n", "esc"),
+			key.WithHelp("n", "keep"),
+		),
+
+		// Sorting.
+		CycleSortOrder: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort"),
+		),
+
+		// Grouping.
+		CollapseGroup: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "collapse group"),
+		),
+		ToggleGroup: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "toggle group"),
+		),
+		ExpandGroups: key.NewBinding(
+			key.WithKeys("+", "="),
+			key.WithHelp("+", "expand groups"),
+		),
+
 		// Filtering.
 		CancelWhileFiltering: key.NewBinding(
 			key.WithKeys("esc"),
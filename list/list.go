@@ -4,10 +4,12 @@
 package list
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -20,8 +22,17 @@ import (
 	"github.com/charmbracelet/bubbles/paginator"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 )
 
+// Internal ID management. Used to make sure messages that are routed to
+// every list are only handled by the one they're meant for.
+var lastID int64
+
+func nextID() int {
+	return int(atomic.AddInt64(&lastID, 1))
+}
+
 // Item is an item that appears in the list.
 type Item interface {
 	// FilterValue is the value we use when filtering against this item when
@@ -52,10 +63,29 @@ type ItemDelegate interface {
 	Update(msg tea.Msg, m *Model) tea.Cmd
 }
 
+// VariableHeightDelegate is an ItemDelegate whose items aren't all the same
+// height, such as items with wrapped descriptions. Items are packed into
+// pages according to their own height, so that none are cut off at the end
+// of a page. Height is still used to size the paginator.
+type VariableHeightDelegate interface {
+	ItemDelegate
+
+	// HeightFor returns the height of the item at the given index in the
+	// visible items. It should match the number of lines Render writes for
+	// the item.
+	HeightFor(m Model, index int, item Item) int
+}
+
 type filteredItem struct {
-	index   int   // index in the unfiltered list
-	item    Item  // item matched
-	matches []int // rune indices of matched items
+	index       int   // index in the unfiltered list
+	item        Item  // item matched
+	matches     []int // rune indices of matched items
+	descMatches []int // rune indices of matches in the description
+
+	// rune indices of matches in each field searched, by field name
+	fieldMatches map[string][]int
+	rank         int     // position in the filter's ranking
+	score        float64 // score of the match; see Rank.Score
 }
 
 type filteredItems []filteredItem
@@ -70,6 +100,10 @@ func (f filteredItems) items() []Item {
 
 // FilterMatchesMsg contains data about items matched during filtering. The
 // message should be routed to Update for processing.
+//
+// Deprecated: the list no longer sends this message; filter results are
+// delivered by commands returned from Update. As the message doesn't tell
+// which filter run it's from, Update ignores it while a run is in progress.
 type FilterMatchesMsg []filteredItem
 
 // FilterFunc takes a term and a list of strings to search through
@@ -77,12 +111,38 @@ type FilterMatchesMsg []filteredItem
 // It should return a sorted list of ranks.
 type FilterFunc func(string, []string) []Rank
 
+// ItemFilterFunc is like FilterFunc, but it's given the items themselves
+// rather than their FilterValue, for filters that need to look at more than
+// that. Matched indexes in the returned ranks should still refer to the
+// runes of each item's FilterValue so that matches can be highlighted.
+type ItemFilterFunc func(string, []Item) []Rank
+
 // Rank defines a rank for a given item.
 type Rank struct {
 	// The index of the item in the original input.
 	Index int
-	// Indices of the actual word that were matched against the filter term.
+	// Indices of the runes of the actual word that were matched against the
+	// filter term.
+	//
+	// These are rune indices rather than byte offsets, so that matches in
+	// non-ASCII text are highlighted correctly. Filters built on
+	// sahilm/fuzzy, which reports byte offsets, need to convert them, as
+	// DefaultFilter does. The same goes for the other indexes below.
 	MatchedIndexes []int
+	// Indices of the runes matched in the item's description, for filters
+	// that search it as well. See DescriptionMatchesForItem.
+	DescriptionMatchedIndexes []int
+	// Indices of the runes matched in each field searched, by field name,
+	// for filters that search several fields. See FieldMatchesForItem.
+	FieldMatchedIndexes map[string][]int
+	// How well the item matched, higher being better, for filters that
+	// score their matches. Matches are ranked by Score, keeping the order
+	// in which they were returned for equal scores, so that matches found
+	// in different chunks are ranked together (see FilterChunkSize).
+	// Filters that leave Score at 0 are thus ranked in the order returned.
+	// Scores are blended with frecency scores when a Frecency is set; see
+	// SetFrecency.
+	Score float64
 }
 
 // DefaultFilter uses the sahilm/fuzzy to filter through the list.
@@ -94,21 +154,22 @@ func DefaultFilter(term string, targets []string) []Rank {
 	for i, r := range ranks {
 		result[i] = Rank{
 			Index:          r.Index,
-			MatchedIndexes: r.MatchedIndexes,
+			MatchedIndexes: byteToRuneIndexes(r.Str, r.MatchedIndexes),
+			Score:          float64(r.Score),
 		}
 	}
 	return result
 }
 
 // UnsortedFilter uses the sahilm/fuzzy to filter through the list. It does not
-// sort the results.
+// sort the results, and leaves their scores at 0 so that they aren't ranked.
 func UnsortedFilter(term string, targets []string) []Rank {
 	ranks := fuzzy.FindNoSort(term, targets)
 	result := make([]Rank, len(ranks))
 	for i, r := range ranks {
 		result[i] = Rank{
 			Index:          r.Index,
-			MatchedIndexes: r.MatchedIndexes,
+			MatchedIndexes: byteToRuneIndexes(r.Str, r.MatchedIndexes),
 		}
 	}
 	return result
@@ -137,6 +198,8 @@ func (f FilterState) String() string {
 
 // Model contains the state of this component.
 type Model struct {
+	id int
+
 	showTitle        bool
 	showFilter       bool
 	showStatusBar    bool
@@ -147,6 +210,10 @@ type Model struct {
 	itemNameSingular string
 	itemNamePlural   string
 
+	// Segments shown on the left and right of the status bar.
+	statusLeft  []StatusSegment
+	statusRight []StatusSegment
+
 	Title             string
 	Styles            Styles
 	InfiniteScrolling bool
@@ -157,6 +224,94 @@ type Model struct {
 	// Filter is used to filter the list.
 	Filter FilterFunc
 
+	// ItemFilter, if set, is used to filter the list instead of Filter, for
+	// filters that look at more than each item's FilterValue, such as
+	// FieldQueryFilter.
+	ItemFilter ItemFilterFunc
+
+	// FilterDebounce delays filtering until the user has stopped typing in
+	// the filter input for this long. If 0 or less, filtering starts on
+	// every keystroke.
+	FilterDebounce time.Duration
+
+	// FilterChunkSize, if greater than 0, filters the items in chunks of this
+	// size, showing matches from each chunk as soon as they're found. Matches
+	// from all chunks so far are ranked together by score, so a better match
+	// found in a later chunk moves ahead of those found earlier.
+	FilterChunkSize int
+
+	// The current filter run. Results from older runs are dropped.
+	// filterPending is set while a debounced run waits to start.
+	filterGen     int
+	filterCancel  context.CancelFunc
+	filterPending bool
+
+	// frecency, if set, blends how often and how recently items were chosen
+	// into the ranking of filter matches.
+	frecency *Frecency
+
+	// SortFilterResults keeps the active sort order while a filter is
+	// applied. By default filtered items are listed in the order they were
+	// ranked by the filter.
+	SortFilterResults bool
+
+	// The orders the list can be sorted by, the active one, and the
+	// unfiltered items in that order. sortedItems is nil when items are
+	// listed in the order they were added.
+	sortOrders  []SortOrder
+	sortIndex   int
+	sortedItems filteredItems
+
+	// Names of the collapsed groups, and the visible items arranged into
+	// groups. sections is nil when no items belong to a group.
+	collapsedGroups map[string]bool
+	groupedItems    filteredItems
+	sections        []groupSection
+	sectionOf       []int // section of each grouped item
+
+	// The tree state of each item in tree mode, and the items in view with
+	// their place in the tree. tree is nil outside of tree mode.
+	tree      []treeNode
+	treeItems filteredItems
+	treeRows  []treeRow
+
+	// Index of the first visible item on each page, when pages don't all
+	// hold the same number of items. If nil, pages hold Paginator.PerPage
+	// items each.
+	pageStarts []int
+
+	// The height available to items, as of the last pagination update.
+	itemsHeight int
+
+	// In scroll mode all items are on a single page, and scrollOffset is
+	// the index of the first item in view.
+	scrollMode    bool
+	showScrollbar bool
+	scrollOffset  int
+
+	// The width of a cell in grid layout. If 0, items are listed one below
+	// the other.
+	gridCellWidth int
+
+	// ScrollOff is the minimum number of items kept in view above and below
+	// the cursor in scroll mode.
+	ScrollOff int
+
+	// XPosition and YPosition are the position of the list's top-left
+	// corner in the terminal window. They're used to find the item under
+	// the mouse.
+	XPosition int
+	YPosition int
+
+	// Whether the mouse wheel moves through the list, and by how many items
+	// at a time. By default the wheel moves by one item.
+	MouseWheelEnabled bool
+	MouseWheelDelta   int
+
+	// The last click, for detecting double-clicks.
+	lastClick      time.Time
+	lastClickIndex int
+
 	disableQuitKeybindings bool
 
 	// Additional key mappings for the short and full help views. This allows
@@ -177,6 +332,19 @@ type Model struct {
 	FilterInput textinput.Model
 	filterState FilterState
 
+	// The size set with SetSize, including the preview pane. width and
+	// height are what's left for the rest of the list.
+	totalWidth  int
+	totalHeight int
+
+	// Preview shows the preview of the selected item, when a PreviewFunc is
+	// set. previewIndex is the GlobalIndex of the previewed item.
+	Preview         viewport.Model
+	previewFunc     PreviewFunc
+	previewPosition PreviewPosition
+	previewRatio    float64
+	previewIndex    int
+
 	// How long status messages should stay visible. By default this is
 	// 1 second.
 	StatusMessageLifetime time.Duration
@@ -187,6 +355,46 @@ type Model struct {
 	// The master set of items we're working with.
 	items []Item
 
+	// Whether multiple items can be marked, and the indexes in items of the
+	// marked ones.
+	multiSelect bool
+	marked      map[int]struct{}
+
+	// Whether items can be moved with the keyboard.
+	reorderable bool
+
+	// Whether the user can remove items, the removal they're asked to
+	// confirm and the last removal, which can be undone until it's
+	// committed. removalGen identifies the last removal.
+	removable      bool
+	pendingRemoval *removal
+	undoRemoval    *removal
+	removalGen     int
+
+	// selectAfterFilter is the GlobalIndex of the item to select once the
+	// running filter run completes, such as an item put back by UndoRemove,
+	// or -1.
+	selectAfterFilter int
+
+	// Whether the action menu is open, and the highlighted action in it.
+	actionMenuOpen bool
+	actionCursor   int
+
+	// FetchSize is the number of items requested from an ItemSource at a
+	// time. If 0 or less, two pages worth of items are requested.
+	FetchSize int
+
+	// State for fetching items from an ItemSource. sourceGen identifies the
+	// source that fetched items are expected from.
+	source       ItemSource
+	sourceCancel context.CancelFunc
+	sourceGen    int
+	sourceTotal  int
+	sourceDone   bool
+	sourceErr    error
+	fetching     bool
+	fetchSpinner bool
+
 	// Filtered items we're currently displaying. Filtering, toggles and so on
 	// will alter this slice so we can show what is relevant. For that reason,
 	// this field should be considered ephemeral.
@@ -216,6 +424,7 @@ func New(items []Item, delegate ItemDelegate, width, height int) Model {
 	p.InactiveDot = styles.InactivePaginationDot.String()
 
 	m := Model{
+		id:                    nextID(),
 		showTitle:             true,
 		showFilter:            true,
 		showStatusBar:         true,
@@ -223,6 +432,7 @@ func New(items []Item, delegate ItemDelegate, width, height int) Model {
 		showHelp:              true,
 		itemNameSingular:      "item",
 		itemNamePlural:        "items",
+		statusLeft:            DefaultStatusSegments(),
 		filteringEnabled:      true,
 		KeyMap:                DefaultKeyMap(),
 		Filter:                DefaultFilter,
@@ -230,14 +440,22 @@ func New(items []Item, delegate ItemDelegate, width, height int) Model {
 		Title:                 "List",
 		FilterInput:           filterInput,
 		StatusMessageLifetime: time.Second,
+		MouseWheelEnabled:     true,
+		MouseWheelDelta:       1,
+
+		width:        width,
+		height:       height,
+		totalWidth:   width,
+		totalHeight:  height,
+		delegate:     delegate,
+		items:        items,
+		Paginator:    p,
+		spinner:      sp,
+		Help:         help.New(),
+		Preview:      viewport.New(0, 0),
+		previewRatio: defaultPreviewRatio,
 
-		width:     width,
-		height:    height,
-		delegate:  delegate,
-		items:     items,
-		Paginator: p,
-		spinner:   sp,
-		Help:      help.New(),
+		selectAfterFilter: -1,
 	}
 
 	m.updatePagination()
@@ -277,10 +495,7 @@ func (m *Model) SetShowTitle(v bool) {
 func (m *Model) SetFilterText(filter string) {
 	m.filterState = Filtering
 	m.FilterInput.SetValue(filter)
-	cmd := filterItems(*m)
-	msg := cmd()
-	fmm, _ := msg.(FilterMatchesMsg)
-	m.filteredItems = filteredItems(fmm)
+	m.filterNow()
 	m.filterState = FilterApplied
 	m.Paginator.Page = 0
 	m.cursor = 0
@@ -296,6 +511,7 @@ func (m *Model) SetFilterState(state FilterState) {
 	m.filterState = state
 	m.FilterInput.CursorEnd()
 	m.FilterInput.Focus()
+	m.updatePagination()
 	m.updateKeybindings()
 }
 
@@ -379,10 +595,14 @@ func (m Model) Items() []Item {
 func (m *Model) SetItems(i []Item) tea.Cmd {
 	var cmd tea.Cmd
 	m.items = i
+	m.marked = nil
+	m.tree = nil
+	m.previewIndex = -1 // the items at each index have changed
+	m.resort()
 
 	if m.filterState != Unfiltered {
 		m.filteredItems = nil
-		cmd = filterItems(*m)
+		cmd = m.filterCmd()
 	}
 
 	m.updatePagination()
@@ -392,8 +612,11 @@ func (m *Model) SetItems(i []Item) tea.Cmd {
 
 // Select selects the given index of the list and goes to its respective page.
 func (m *Model) Select(index int) {
-	m.Paginator.Page = index / m.Paginator.PerPage
-	m.cursor = index % m.Paginator.PerPage
+	m.Paginator.Page = m.pageOf(index)
+	start, _ := m.pageBounds(m.Paginator.Page)
+	m.cursor = index - start
+	m.updateScroll()
+	m.updatePreview()
 }
 
 // ResetSelected resets the selected item to the first item in the first page of the list.
@@ -410,9 +633,10 @@ func (m *Model) ResetFilter() {
 func (m *Model) SetItem(index int, item Item) tea.Cmd {
 	var cmd tea.Cmd
 	m.items[index] = item
+	m.resort()
 
 	if m.filterState != Unfiltered {
-		cmd = filterItems(*m)
+		cmd = m.filterCmd()
 	}
 
 	m.updatePagination()
@@ -423,10 +647,13 @@ func (m *Model) SetItem(index int, item Item) tea.Cmd {
 // the item will be appended. This returns a command.
 func (m *Model) InsertItem(index int, item Item) tea.Cmd {
 	var cmd tea.Cmd
+	m.shiftMarks(max(0, index), 1)
+	m.insertTreeNode(index)
 	m.items = insertItemIntoSlice(m.items, item, index)
+	m.resort()
 
 	if m.filterState != Unfiltered {
-		cmd = filterItems(*m)
+		cmd = m.filterCmd()
 	}
 
 	m.updatePagination()
@@ -438,9 +665,21 @@ func (m *Model) InsertItem(index int, item Item) tea.Cmd {
 // this will be a no-op. O(n) complexity, which probably won't matter in the
 // case of a TUI.
 func (m *Model) RemoveItem(index int) {
+	if index < 0 || index >= len(m.items) {
+		return
+	}
+	m.shiftMarks(index, -1)
+	m.removeTreeNode(index)
 	m.items = removeItemFromSlice(m.items, index)
+	m.resort()
 	if m.filterState != Unfiltered {
-		m.filteredItems = removeFilterMatchFromSlice(m.filteredItems, index)
+		if m.filterCancel != nil {
+			// The results of the running filter refer to the items as they
+			// were, so filter them again instead.
+			m.filterNow()
+		} else {
+			m.filteredItems = removeFilterMatch(m.filteredItems, index)
+		}
 		if len(m.filteredItems) == 0 {
 			m.resetFiltering()
 		}
@@ -456,22 +695,54 @@ func (m *Model) SetDelegate(d ItemDelegate) {
 
 // VisibleItems returns the total items available to be shown.
 func (m Model) VisibleItems() []Item {
-	if m.filterState != Unfiltered {
-		return m.filteredItems.items()
+	if v := m.visibleItems(); v != nil || m.filterState != Unfiltered {
+		return v.items()
 	}
 	return m.items
 }
 
+// visibleCount returns the number of items available to be shown, without
+// building a slice of them as VisibleItems does.
+func (m Model) visibleCount() int {
+	if v := m.visibleItems(); v != nil || m.filterState != Unfiltered {
+		return len(v)
+	}
+	return len(m.items)
+}
+
+// visibleItems returns the items available to be shown, along with their
+// indexes in the unfiltered list. It returns nil if the items are shown as
+// they are stored.
+func (m Model) visibleItems() filteredItems {
+	if m.sections != nil {
+		return m.groupedItems
+	}
+	if m.tree != nil {
+		return m.treeItems
+	}
+	return m.ungroupedItems()
+}
+
+// ungroupedItems returns the visible items before they're arranged into
+// groups. It returns nil if the items are shown as they are stored.
+func (m Model) ungroupedItems() filteredItems {
+	if m.filterState != Unfiltered {
+		return m.filteredItems
+	}
+	return m.sortedItems
+}
+
 // SelectedItem returns the current selected item in the list.
 func (m Model) SelectedItem() Item {
 	i := m.Index()
-
-	items := m.VisibleItems()
-	if i < 0 || len(items) == 0 || len(items) <= i {
+	if i < 0 || i >= m.visibleCount() {
 		return nil
 	}
 
-	return items[i]
+	if v := m.visibleItems(); v != nil || m.filterState != Unfiltered {
+		return v[i].item
+	}
+	return m.items[i]
 }
 
 // MatchesForItem returns rune positions matched by the current filter, if any.
@@ -479,10 +750,33 @@ func (m Model) SelectedItem() Item {
 //
 // See DefaultItemView for a usage example.
 func (m Model) MatchesForItem(index int) []int {
-	if m.filteredItems == nil || index >= len(m.filteredItems) {
+	v := m.visibleItems()
+	if v == nil || index >= len(v) {
 		return nil
 	}
-	return m.filteredItems[index].matches
+	return v[index].matches
+}
+
+// DescriptionMatchesForItem returns rune positions matched by the current
+// filter in the item's description, if the filter searches descriptions.
+// See Rank.
+func (m Model) DescriptionMatchesForItem(index int) []int {
+	v := m.visibleItems()
+	if v == nil || index >= len(v) {
+		return nil
+	}
+	return v[index].descMatches
+}
+
+// FieldMatchesForItem returns rune positions matched by the current filter
+// in the named field of the item, if the filter searches several fields.
+// See MultiFieldFilter.
+func (m Model) FieldMatchesForItem(index int, field string) []int {
+	v := m.visibleItems()
+	if v == nil || index >= len(v) {
+		return nil
+	}
+	return v[index].fieldMatches[field]
 }
 
 // Index returns the index of the currently selected item as it is stored in the
@@ -490,29 +784,44 @@ func (m Model) MatchesForItem(index int) []int {
 // Using this value with SetItem() might be incorrect, consider using
 // GlobalIndex() instead.
 func (m Model) Index() int {
-	return m.Paginator.Page*m.Paginator.PerPage + m.cursor
+	start, _ := m.pageBounds(m.Paginator.Page)
+	return start + m.cursor
 }
 
 // GlobalIndex returns the index of the currently selected item as it is stored
 // in the unfiltered list of items. This value can be used with SetItem().
 func (m Model) GlobalIndex() int {
-	index := m.Index()
+	return m.globalIndexOf(m.Index())
+}
 
-	if m.filteredItems == nil || index >= len(m.filteredItems) {
+// globalIndexOf maps an index in the visible items to the index of the same
+// item in the unfiltered list of items.
+func (m Model) globalIndexOf(index int) int {
+	v := m.visibleItems()
+	if v == nil || index >= len(v) {
 		return index
 	}
 
-	return m.filteredItems[index].index
+	return v[index].index
 }
 
-// Cursor returns the index of the cursor on the current page.
+// Cursor returns the index of the cursor on the current page. In scroll
+// mode this is relative to the first item in view.
 func (m Model) Cursor() int {
+	if m.scrollMode {
+		return m.cursor - m.scrollOffset
+	}
 	return m.cursor
 }
 
 // CursorUp moves the cursor up. This can also move the state to the previous
 // page.
 func (m *Model) CursorUp() {
+	if m.gridCellWidth > 0 {
+		m.moveInGrid(-m.GridColumns())
+		return
+	}
+	defer m.updateScroll()
 	m.cursor--
 
 	// If we're at the start, stop
@@ -520,7 +829,7 @@ func (m *Model) CursorUp() {
 		// if infinite scrolling is enabled, go to the last item
 		if m.InfiniteScrolling {
 			m.Paginator.Page = m.Paginator.TotalPages - 1
-			m.cursor = m.Paginator.ItemsOnPage(len(m.VisibleItems())) - 1
+			m.cursor = m.itemsOnPage() - 1
 			return
 		}
 
@@ -535,13 +844,18 @@ func (m *Model) CursorUp() {
 
 	// Go to the previous page
 	m.Paginator.PrevPage()
-	m.cursor = m.Paginator.ItemsOnPage(len(m.VisibleItems())) - 1
+	m.cursor = m.itemsOnPage() - 1
 }
 
 // CursorDown moves the cursor down. This can also advance the state to the
 // next page.
 func (m *Model) CursorDown() {
-	itemsOnPage := m.Paginator.ItemsOnPage(len(m.VisibleItems()))
+	if m.gridCellWidth > 0 {
+		m.moveInGrid(m.GridColumns())
+		return
+	}
+	defer m.updateScroll()
+	itemsOnPage := m.itemsOnPage()
 
 	m.cursor++
 
@@ -574,13 +888,23 @@ func (m *Model) CursorDown() {
 	}
 }
 
-// PrevPage moves to the previous page, if available.
+// PrevPage moves to the previous page, if available. In scroll mode it
+// scrolls up by the number of items in view.
 func (m *Model) PrevPage() {
+	if m.scrollMode {
+		m.scrollPage(-1)
+		return
+	}
 	m.Paginator.PrevPage()
 }
 
-// NextPage moves to the next page, if available.
+// NextPage moves to the next page, if available. In scroll mode it scrolls
+// down by the number of items in view.
 func (m *Model) NextPage() {
+	if m.scrollMode {
+		m.scrollPage(1)
+		return
+	}
 	m.Paginator.NextPage()
 }
 
@@ -615,12 +939,12 @@ func (m Model) IsFiltered() bool {
 
 // Width returns the current width setting.
 func (m Model) Width() int {
-	return m.width
+	return m.totalWidth
 }
 
 // Height returns the current height setting.
 func (m Model) Height() int {
-	return m.height
+	return m.totalHeight
 }
 
 // SetSpinner allows to set the spinner style.
@@ -680,17 +1004,21 @@ func (m *Model) SetSize(width, height int) {
 
 // SetWidth sets the width of this component.
 func (m *Model) SetWidth(v int) {
-	m.setSize(v, m.height)
+	m.setSize(v, m.totalHeight)
 }
 
 // SetHeight sets the height of this component.
 func (m *Model) SetHeight(v int) {
-	m.setSize(m.width, v)
+	m.setSize(m.totalWidth, v)
 }
 
 func (m *Model) setSize(width, height int) {
 	promptWidth := lipgloss.Width(m.Styles.Title.Render(m.FilterInput.Prompt))
 
+	m.totalWidth = width
+	m.totalHeight = height
+	width, height = m.layoutPreview(width, height)
+
 	m.width = width
 	m.height = height
 	m.Help.Width = width
@@ -703,6 +1031,7 @@ func (m *Model) resetFiltering() {
 		return
 	}
 
+	m.cancelFilter()
 	m.filterState = Unfiltered
 	m.FilterInput.Reset()
 	m.filteredItems = nil
@@ -714,7 +1043,9 @@ func (m Model) itemsAsFilterItems() filteredItems {
 	fi := make([]filteredItem, len(m.items))
 	for i, item := range m.items {
 		fi[i] = filteredItem{
-			item: item,
+			index: i,
+			item:  item,
+			rank:  i,
 		}
 	}
 	return fi
@@ -726,12 +1057,41 @@ func (m *Model) updateKeybindings() {
 	case Filtering:
 		m.KeyMap.CursorUp.SetEnabled(false)
 		m.KeyMap.CursorDown.SetEnabled(false)
+		m.KeyMap.CursorLeft.SetEnabled(false)
+		m.KeyMap.CursorRight.SetEnabled(false)
 		m.KeyMap.NextPage.SetEnabled(false)
 		m.KeyMap.PrevPage.SetEnabled(false)
 		m.KeyMap.GoToStart.SetEnabled(false)
 		m.KeyMap.GoToEnd.SetEnabled(false)
 		m.KeyMap.Filter.SetEnabled(false)
 		m.KeyMap.ClearFilter.SetEnabled(false)
+		m.KeyMap.ToggleMark.SetEnabled(false)
+		m.KeyMap.MarkAll.SetEnabled(false)
+		m.KeyMap.UnmarkAll.SetEnabled(false)
+		m.KeyMap.MarkRangeUp.SetEnabled(false)
+		m.KeyMap.MarkRangeDown.SetEnabled(false)
+		m.KeyMap.CycleSortOrder.SetEnabled(false)
+		m.KeyMap.CollapseGroup.SetEnabled(false)
+		m.KeyMap.ToggleGroup.SetEnabled(false)
+		m.KeyMap.ExpandGroups.SetEnabled(false)
+		m.KeyMap.MoveUp.SetEnabled(false)
+		m.KeyMap.MoveDown.SetEnabled(false)
+		m.KeyMap.MoveToTop.SetEnabled(false)
+		m.KeyMap.MoveToBottom.SetEnabled(false)
+		m.KeyMap.ExpandNode.SetEnabled(false)
+		m.KeyMap.CollapseNode.SetEnabled(false)
+		m.KeyMap.GoToParent.SetEnabled(false)
+		m.KeyMap.PreviewUp.SetEnabled(false)
+		m.KeyMap.PreviewDown.SetEnabled(false)
+		m.KeyMap.PreviewHalfPageUp.SetEnabled(false)
+		m.KeyMap.PreviewHalfPageDown.SetEnabled(false)
+		m.KeyMap.OpenActionMenu.SetEnabled(false)
+		m.KeyMap.ChooseAction.SetEnabled(false)
+		m.KeyMap.CloseActionMenu.SetEnabled(false)
+		m.KeyMap.RemoveItem.SetEnabled(false)
+		m.KeyMap.UndoRemove.SetEnabled(false)
+		m.KeyMap.ConfirmRemove.SetEnabled(m.pendingRemoval != nil)
+		m.KeyMap.CancelRemove.SetEnabled(m.pendingRemoval != nil)
 		m.KeyMap.CancelWhileFiltering.SetEnabled(true)
 		m.KeyMap.AcceptWhileFiltering.SetEnabled(m.FilterInput.Value() != "")
 		m.KeyMap.Quit.SetEnabled(false)
@@ -742,8 +1102,10 @@ func (m *Model) updateKeybindings() {
 		hasItems := len(m.items) != 0
 		m.KeyMap.CursorUp.SetEnabled(hasItems)
 		m.KeyMap.CursorDown.SetEnabled(hasItems)
+		m.KeyMap.CursorLeft.SetEnabled(hasItems && m.gridCellWidth > 0)
+		m.KeyMap.CursorRight.SetEnabled(hasItems && m.gridCellWidth > 0)
 
-		hasPages := m.Paginator.TotalPages > 1
+		hasPages := m.Paginator.TotalPages > 1 || (m.scrollMode && hasItems)
 		m.KeyMap.NextPage.SetEnabled(hasPages)
 		m.KeyMap.PrevPage.SetEnabled(hasPages)
 
@@ -752,6 +1114,46 @@ func (m *Model) updateKeybindings() {
 
 		m.KeyMap.Filter.SetEnabled(m.filteringEnabled && hasItems)
 		m.KeyMap.ClearFilter.SetEnabled(m.filterState == FilterApplied)
+
+		canMark := m.multiSelect && hasItems
+		m.KeyMap.ToggleMark.SetEnabled(canMark)
+		m.KeyMap.MarkAll.SetEnabled(canMark)
+		m.KeyMap.UnmarkAll.SetEnabled(canMark)
+		m.KeyMap.MarkRangeUp.SetEnabled(canMark)
+		m.KeyMap.MarkRangeDown.SetEnabled(canMark)
+
+		m.KeyMap.CycleSortOrder.SetEnabled(len(m.sortOrders) > 1)
+
+		m.KeyMap.CollapseGroup.SetEnabled(m.sections != nil && m.visibleCount() > 0)
+		m.KeyMap.ToggleGroup.SetEnabled(m.sections != nil)
+		m.KeyMap.ExpandGroups.SetEnabled(len(m.collapsedGroups) > 0)
+
+		canReorder := m.canReorder()
+		m.KeyMap.MoveUp.SetEnabled(canReorder)
+		m.KeyMap.MoveDown.SetEnabled(canReorder)
+		m.KeyMap.MoveToTop.SetEnabled(canReorder)
+		m.KeyMap.MoveToBottom.SetEnabled(canReorder)
+
+		inTree := m.tree != nil && hasItems
+		m.KeyMap.ExpandNode.SetEnabled(inTree)
+		m.KeyMap.CollapseNode.SetEnabled(inTree)
+		m.KeyMap.GoToParent.SetEnabled(inTree)
+
+		hasPreview := m.previewFunc != nil
+		m.KeyMap.PreviewUp.SetEnabled(hasPreview)
+		m.KeyMap.PreviewDown.SetEnabled(hasPreview)
+		m.KeyMap.PreviewHalfPageUp.SetEnabled(hasPreview)
+		m.KeyMap.PreviewHalfPageDown.SetEnabled(hasPreview)
+
+		m.KeyMap.OpenActionMenu.SetEnabled(hasItems && !m.actionMenuOpen)
+		m.KeyMap.ChooseAction.SetEnabled(m.actionMenuOpen)
+		m.KeyMap.CloseActionMenu.SetEnabled(m.actionMenuOpen)
+
+		m.KeyMap.RemoveItem.SetEnabled(m.removable && hasItems && m.pendingRemoval == nil)
+		m.KeyMap.UndoRemove.SetEnabled(m.undoRemoval != nil)
+		m.KeyMap.ConfirmRemove.SetEnabled(m.pendingRemoval != nil)
+		m.KeyMap.CancelRemove.SetEnabled(m.pendingRemoval != nil)
+
 		m.KeyMap.CancelWhileFiltering.SetEnabled(false)
 		m.KeyMap.AcceptWhileFiltering.SetEnabled(false)
 		m.KeyMap.Quit.SetEnabled(!m.disableQuitKeybindings)
@@ -770,6 +1172,8 @@ func (m *Model) updateKeybindings() {
 // Update pagination according to the amount of items for the current state.
 func (m *Model) updatePagination() {
 	index := m.Index()
+	m.retree()
+	m.regroup()
 	availHeight := m.height
 
 	if m.showTitle || (m.showFilter && m.filteringEnabled) {
@@ -778,24 +1182,35 @@ func (m *Model) updatePagination() {
 	if m.showStatusBar {
 		availHeight -= lipgloss.Height(m.statusView())
 	}
-	if m.showPagination {
+	if m.showPagination && !m.scrollMode {
 		availHeight -= lipgloss.Height(m.paginationView())
 	}
 	if m.showHelp {
 		availHeight -= lipgloss.Height(m.helpView())
 	}
 
-	m.Paginator.PerPage = max(1, availHeight/(m.delegate.Height()+m.delegate.Spacing()))
+	rows := max(1, availHeight/(m.delegate.Height()+m.delegate.Spacing()))
+	m.Paginator.PerPage = rows * m.GridColumns()
 
-	if pages := len(m.VisibleItems()); pages < 1 {
+	m.itemsHeight = availHeight
+	m.pageStarts = nil
+	_, variableHeight := m.delegate.(VariableHeightDelegate)
+	if m.scrollMode {
+		m.pageStarts = []int{0}
+	} else if (variableHeight && m.gridCellWidth <= 0) || m.sections != nil {
+		m.pageStarts = m.packPages(availHeight)
+	}
+
+	if m.pageStarts != nil {
+		m.Paginator.TotalPages = len(m.pageStarts)
+	} else if pages := m.visibleCount(); pages < 1 {
 		m.Paginator.SetTotalPages(1)
 	} else {
 		m.Paginator.SetTotalPages(pages)
 	}
 
 	// Restore index
-	m.Paginator.Page = index / m.Paginator.PerPage
-	m.cursor = index % m.Paginator.PerPage
+	m.Select(index)
 
 	// Make sure the page stays in bounds
 	if m.Paginator.Page >= m.Paginator.TotalPages-1 {
@@ -821,8 +1236,30 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 
 	case FilterMatchesMsg:
-		m.filteredItems = filteredItems(msg)
-		return m, nil
+		if m.filterCancel != nil {
+			return m, nil
+		}
+		return m, m.handleFilterResult(filterResultMsg{
+			id: m.id, gen: m.filterGen, matches: filteredItems(msg), first: true,
+		})
+
+	case filterDebounceMsg:
+		if msg.id != m.id || msg.gen != m.filterGen {
+			return m, nil
+		}
+		return m, m.filterCmd()
+
+	case filterResultMsg:
+		if msg.id != m.id || msg.gen != m.filterGen {
+			return m, nil
+		}
+		return m, m.handleFilterResult(msg)
+
+	case itemsFetchedMsg:
+		if msg.id != m.id || msg.gen != m.sourceGen {
+			return m, nil
+		}
+		return m, m.handleFetched(msg)
 
 	case spinner.TickMsg:
 		newSpinnerModel, cmd := m.spinner.Update(msg)
@@ -833,21 +1270,113 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 	case statusMessageTimeoutMsg:
 		m.hideStatusMessage()
+
+	case undoTimeoutMsg:
+		if msg.id != m.id || msg.gen != m.removalGen {
+			return m, nil
+		}
+		return m, m.commitRemoval()
 	}
 
-	if m.filterState == Filtering {
+	switch {
+	case m.pendingRemoval != nil:
+		cmds = append(cmds, m.handleRemovalPrompt(msg))
+	case m.filterState == Filtering:
 		cmds = append(cmds, m.handleFiltering(msg))
-	} else {
+	case m.actionMenuOpen:
+		cmds = append(cmds, m.handleActionMenu(msg))
+	default:
 		cmds = append(cmds, m.handleBrowsing(msg))
 	}
+	m.updatePreview()
 
 	return m, tea.Batch(cmds...)
 }
 
+// pageOf returns the page that the item at the given index in the visible
+// items is on.
+func (m Model) pageOf(index int) int {
+	if m.pageStarts == nil {
+		return index / m.Paginator.PerPage
+	}
+	return max(0, sort.SearchInts(m.pageStarts, index+1)-1)
+}
+
+// pageBounds returns the range of visible items on the given page.
+func (m Model) pageBounds(page int) (start, end int) {
+	numItems := m.visibleCount()
+	if m.pageStarts == nil {
+		start = page * m.Paginator.PerPage
+		return start, max(start, min(start+m.Paginator.PerPage, numItems))
+	}
+	if page >= len(m.pageStarts) {
+		return numItems, numItems
+	}
+	start, end = m.pageStarts[page], numItems
+	if page+1 < len(m.pageStarts) {
+		end = m.pageStarts[page+1]
+	}
+	return start, end
+}
+
+// packPages splits the visible items into pages that fit in the given height,
+// counting the height of each item and the rows taken by section headers,
+// and returns the index of the first item on each page. Every page holds at
+// least one item. An item taller than a page gets a page of its own, and is
+// clipped to fit when rendered; see itemsView.
+func (m Model) packPages(availHeight int) []int {
+	var (
+		items  = m.VisibleItems()
+		starts = []int{0}
+		used   int
+	)
+
+	for i := range items {
+		c := m.rowsFor(items, i, starts[len(starts)-1])
+		if used > 0 && used+c > availHeight {
+			starts = append(starts, i)
+			used = 0
+			c = m.rowsFor(items, i, i)
+		}
+		used += c
+	}
+	return starts
+}
+
+// rowsFor returns the number of rows taken by the visible item at the given
+// index when rendered on a page starting at pageStart, including spacing and
+// any section headers above it.
+func (m Model) rowsFor(items []Item, index, pageStart int) int {
+	spacing := m.delegate.Spacing()
+	rows := m.itemHeight(index, items[index]) + spacing
+	if m.sections != nil {
+		headers := len(m.headersBefore(index, pageStart))
+		if index == len(items)-1 {
+			headers += len(m.trailingSections())
+		}
+		rows += headers * (lipgloss.Height(m.groupHeaderView(groupSection{})) + spacing)
+	}
+	return rows
+}
+
+// itemHeight returns the height of the item at the given index in the
+// visible items.
+func (m Model) itemHeight(index int, item Item) int {
+	if d, ok := m.delegate.(VariableHeightDelegate); ok && m.gridCellWidth <= 0 {
+		return max(1, d.HeightFor(m, index, item))
+	}
+	return m.delegate.Height()
+}
+
+// itemsOnPage returns the number of items on the current page.
+func (m Model) itemsOnPage() int {
+	start, end := m.pageBounds(m.Paginator.Page)
+	return end - start
+}
+
 // Updates for when a user is browsing the list.
 func (m *Model) handleBrowsing(msg tea.Msg) tea.Cmd {
 	var cmds []tea.Cmd
-	numItems := len(m.VisibleItems())
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -866,11 +1395,80 @@ func (m *Model) handleBrowsing(msg tea.Msg) tea.Cmd {
 		case key.Matches(msg, m.KeyMap.CursorDown):
 			m.CursorDown()
 
+		case key.Matches(msg, m.KeyMap.CursorLeft):
+			m.CursorLeft()
+
+		case key.Matches(msg, m.KeyMap.CursorRight):
+			m.CursorRight()
+
+		case key.Matches(msg, m.KeyMap.ToggleMark):
+			m.ToggleMark()
+
+		case key.Matches(msg, m.KeyMap.MarkAll):
+			m.MarkAll()
+
+		case key.Matches(msg, m.KeyMap.UnmarkAll):
+			m.UnmarkAll()
+
+		case key.Matches(msg, m.KeyMap.MarkRangeUp):
+			m.markRange(true)
+
+		case key.Matches(msg, m.KeyMap.MarkRangeDown):
+			m.markRange(false)
+
+		case key.Matches(msg, m.KeyMap.CycleSortOrder):
+			m.CycleSortOrder()
+
+		case key.Matches(msg, m.KeyMap.OpenActionMenu):
+			m.OpenActionMenu()
+
+		case key.Matches(msg, m.KeyMap.RemoveItem):
+			if m.visibleCount() > 0 {
+				m.ConfirmRemoveItem(m.GlobalIndex())
+			}
+
+		case key.Matches(msg, m.KeyMap.UndoRemove):
+			cmds = append(cmds, m.UndoRemove())
+
+		case key.Matches(msg, m.KeyMap.MoveUp):
+			cmds = append(cmds, m.moveSelected(m.Index()-1))
+
+		case key.Matches(msg, m.KeyMap.MoveDown):
+			cmds = append(cmds, m.moveSelected(m.Index()+1))
+
+		case key.Matches(msg, m.KeyMap.MoveToTop):
+			cmds = append(cmds, m.moveSelected(0))
+
+		case key.Matches(msg, m.KeyMap.MoveToBottom):
+			cmds = append(cmds, m.moveSelected(m.visibleCount()-1))
+
+		case key.Matches(msg, m.KeyMap.PreviewUp, m.KeyMap.PreviewDown,
+			m.KeyMap.PreviewHalfPageUp, m.KeyMap.PreviewHalfPageDown):
+			m.scrollPreview(msg)
+
+		case key.Matches(msg, m.KeyMap.ExpandNode):
+			m.ExpandNode()
+
+		case key.Matches(msg, m.KeyMap.CollapseNode):
+			m.CollapseNode()
+
+		case key.Matches(msg, m.KeyMap.GoToParent):
+			m.GoToParent()
+
+		case key.Matches(msg, m.KeyMap.CollapseGroup):
+			m.CollapseGroup()
+
+		case key.Matches(msg, m.KeyMap.ToggleGroup):
+			m.ToggleGroup()
+
+		case key.Matches(msg, m.KeyMap.ExpandGroups):
+			m.ExpandAllGroups()
+
 		case key.Matches(msg, m.KeyMap.PrevPage):
-			m.Paginator.PrevPage()
+			m.PrevPage()
 
 		case key.Matches(msg, m.KeyMap.NextPage):
-			m.Paginator.NextPage()
+			m.NextPage()
 
 		case key.Matches(msg, m.KeyMap.GoToStart):
 			m.Paginator.Page = 0
@@ -878,17 +1476,19 @@ func (m *Model) handleBrowsing(msg tea.Msg) tea.Cmd {
 
 		case key.Matches(msg, m.KeyMap.GoToEnd):
 			m.Paginator.Page = m.Paginator.TotalPages - 1
-			m.cursor = m.Paginator.ItemsOnPage(numItems) - 1
+			m.cursor = max(0, m.itemsOnPage()-1)
 
 		case key.Matches(msg, m.KeyMap.Filter):
 			m.hideStatusMessage()
 			if m.FilterInput.Value() == "" {
 				// Populate filter with all items only if the filter is empty.
 				m.filteredItems = m.itemsAsFilterItems()
+				m.sortFilteredItems()
 			}
 			m.Paginator.Page = 0
 			m.cursor = 0
 			m.filterState = Filtering
+			m.updatePagination()
 			m.FilterInput.CursorEnd()
 			m.FilterInput.Focus()
 			m.updateKeybindings()
@@ -899,17 +1499,30 @@ func (m *Model) handleBrowsing(msg tea.Msg) tea.Cmd {
 		case key.Matches(msg, m.KeyMap.CloseFullHelp):
 			m.Help.ShowAll = !m.Help.ShowAll
 			m.updatePagination()
+
+		default:
+			// Actions only get keys the list doesn't use itself.
+			if cmd := m.matchAction(msg); cmd != nil {
+				return cmd
+			}
 		}
+
+	case tea.MouseMsg:
+		cmds = append(cmds, m.handleMouse(msg))
 	}
 
 	cmd := m.delegate.Update(msg, m)
 	cmds = append(cmds, cmd)
 
 	// Keep the index in bounds when paginating
-	itemsOnPage := m.Paginator.ItemsOnPage(len(m.VisibleItems()))
+	itemsOnPage := m.itemsOnPage()
 	if m.cursor > itemsOnPage-1 {
 		m.cursor = max(0, itemsOnPage-1)
 	}
+	m.updateScroll()
+
+	// Load more items from the source when nearing the end of the list.
+	cmds = append(cmds, m.maybeFetchMore())
 
 	return tea.Batch(cmds...)
 }
@@ -933,6 +1546,12 @@ func (m *Model) handleFiltering(msg tea.Msg) tea.Cmd {
 				break
 			}
 
+			// Wait no longer for a filter run that hasn't finished, so
+			// that the check below sees its matches.
+			if m.filterPending || m.filterCancel != nil {
+				m.filterNow()
+			}
+
 			h := m.VisibleItems()
 
 			// If we've filtered down to nothing, clear the filter
@@ -959,7 +1578,7 @@ func (m *Model) handleFiltering(msg tea.Msg) tea.Cmd {
 
 	// If the filtering input has changed, request updated filtering
 	if filterChanged {
-		cmds = append(cmds, filterItems(*m))
+		cmds = append(cmds, m.requestFilter())
 		m.KeyMap.AcceptWhileFiltering.SetEnabled(m.FilterInput.Value() != "")
 	}
 
@@ -972,9 +1591,28 @@ func (m *Model) handleFiltering(msg tea.Msg) tea.Cmd {
 // ShortHelp returns bindings to show in the abbreviated help view. It's part
 // of the help.KeyMap interface.
 func (m Model) ShortHelp() []key.Binding {
+	if m.pendingRemoval != nil {
+		return []key.Binding{
+			m.KeyMap.ConfirmRemove,
+			m.KeyMap.CancelRemove,
+		}
+	}
+
 	kb := []key.Binding{
 		m.KeyMap.CursorUp,
 		m.KeyMap.CursorDown,
+		m.KeyMap.CursorLeft,
+		m.KeyMap.CursorRight,
+		m.KeyMap.ToggleMark,
+	}
+
+	if m.actionMenuOpen {
+		return []key.Binding{
+			m.KeyMap.CursorUp,
+			m.KeyMap.CursorDown,
+			m.KeyMap.ChooseAction,
+			m.KeyMap.CloseActionMenu,
+		}
 	}
 
 	filtering := m.filterState == Filtering
@@ -987,6 +1625,16 @@ func (m Model) ShortHelp() []key.Binding {
 		}
 	}
 
+	// Then the actions of the selected item.
+	if !filtering && len(m.SelectedActions()) > 0 {
+		kb = append(kb, m.actionHelp()...)
+		kb = append(kb, m.KeyMap.OpenActionMenu)
+	}
+
+	if !filtering && m.removable {
+		kb = append(kb, m.KeyMap.RemoveItem, m.KeyMap.UndoRemove)
+	}
+
 	kb = append(kb,
 		m.KeyMap.Filter,
 		m.KeyMap.ClearFilter,
@@ -1007,15 +1655,76 @@ func (m Model) ShortHelp() []key.Binding {
 // FullHelp returns bindings to show the full help view. It's part of the
 // help.KeyMap interface.
 func (m Model) FullHelp() [][]key.Binding {
+	if m.pendingRemoval != nil {
+		return [][]key.Binding{{
+			m.KeyMap.ConfirmRemove,
+			m.KeyMap.CancelRemove,
+		}}
+	}
+
+	if m.actionMenuOpen {
+		return [][]key.Binding{{
+			m.KeyMap.CursorUp,
+			m.KeyMap.CursorDown,
+			m.KeyMap.ChooseAction,
+			m.KeyMap.CloseActionMenu,
+		}}
+	}
+
 	kb := [][]key.Binding{{
 		m.KeyMap.CursorUp,
 		m.KeyMap.CursorDown,
-		m.KeyMap.NextPage,
-		m.KeyMap.PrevPage,
+		m.KeyMap.CursorLeft,
+		m.KeyMap.CursorRight,
+		m.pageHelp(m.KeyMap.NextPage),
+		m.pageHelp(m.KeyMap.PrevPage),
 		m.KeyMap.GoToStart,
 		m.KeyMap.GoToEnd,
 	}}
 
+	if m.multiSelect {
+		kb = append(kb, []key.Binding{
+			m.KeyMap.ToggleMark,
+			m.KeyMap.MarkAll,
+			m.KeyMap.UnmarkAll,
+			m.KeyMap.MarkRangeUp,
+			m.KeyMap.MarkRangeDown,
+		})
+	}
+
+	if m.previewFunc != nil {
+		kb = append(kb, []key.Binding{
+			m.KeyMap.PreviewUp,
+			m.KeyMap.PreviewDown,
+			m.KeyMap.PreviewHalfPageUp,
+			m.KeyMap.PreviewHalfPageDown,
+		})
+	}
+
+	if m.tree != nil {
+		kb = append(kb, []key.Binding{
+			m.KeyMap.ExpandNode,
+			m.KeyMap.CollapseNode,
+			m.KeyMap.GoToParent,
+		})
+	}
+
+	if m.removable {
+		kb = append(kb, []key.Binding{
+			m.KeyMap.RemoveItem,
+			m.KeyMap.UndoRemove,
+		})
+	}
+
+	if m.reorderable {
+		kb = append(kb, []key.Binding{
+			m.KeyMap.MoveUp,
+			m.KeyMap.MoveDown,
+			m.KeyMap.MoveToTop,
+			m.KeyMap.MoveToBottom,
+		})
+	}
+
 	filtering := m.filterState == Filtering
 
 	// If the delegate implements the help.KeyMap interface add full help
@@ -1026,11 +1735,20 @@ func (m Model) FullHelp() [][]key.Binding {
 		}
 	}
 
+	// The actions of the selected item get a section of their own.
+	if !filtering && len(m.SelectedActions()) > 0 {
+		kb = append(kb, append(m.actionHelp(), m.KeyMap.OpenActionMenu))
+	}
+
 	listLevelBindings := []key.Binding{
 		m.KeyMap.Filter,
 		m.KeyMap.ClearFilter,
 		m.KeyMap.AcceptWhileFiltering,
 		m.KeyMap.CancelWhileFiltering,
+		m.KeyMap.CycleSortOrder,
+		m.KeyMap.CollapseGroup,
+		m.KeyMap.ToggleGroup,
+		m.KeyMap.ExpandGroups,
 	}
 
 	if !filtering && m.AdditionalFullHelpKeys != nil {
@@ -1065,7 +1783,7 @@ func (m Model) View() string {
 	}
 
 	var pagination string
-	if m.showPagination {
+	if m.showPagination && !m.scrollMode {
 		pagination = m.paginationView()
 		availHeight -= lipgloss.Height(pagination)
 	}
@@ -1077,9 +1795,12 @@ func (m Model) View() string {
 	}
 
 	content := lipgloss.NewStyle().Height(availHeight).Render(m.populatedView())
+	if m.actionMenuOpen {
+		content = m.actionMenuOverlay(content)
+	}
 	sections = append(sections, content)
 
-	if m.showPagination {
+	if m.showPagination && !m.scrollMode {
 		sections = append(sections, pagination)
 	}
 
@@ -1087,7 +1808,11 @@ func (m Model) View() string {
 		sections = append(sections, help)
 	}
 
-	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+	view := lipgloss.JoinVertical(lipgloss.Left, sections...)
+	if m.previewFunc != nil {
+		return m.previewView(view)
+	}
+	return view
 }
 
 func (m Model) titleView() string {
@@ -1115,8 +1840,16 @@ func (m Model) titleView() string {
 
 		view += m.Styles.Title.Render(m.Title)
 
+		// Sort indicator
+		if o, ok := m.SortOrder(); ok && o.Name != "" {
+			view += " " + m.Styles.SortIndicator.Render(o.Name)
+		}
+
 		// Status message
 		if m.filterState != Filtering {
+			if m.undoRemoval != nil {
+				view += "  " + m.undoHintView()
+			}
 			view += "  " + m.statusMessage
 			view = ansi.Truncate(view, m.width-spinnerWidth, ellipsis)
 		}
@@ -1139,49 +1872,12 @@ func (m Model) titleView() string {
 }
 
 func (m Model) statusView() string {
-	var status string
-
-	totalItems := len(m.items)
-	visibleItems := len(m.VisibleItems())
-
-	var itemName string
-	if visibleItems != 1 {
-		itemName = m.itemNamePlural
-	} else {
-		itemName = m.itemNameSingular
-	}
-
-	itemsDisplay := fmt.Sprintf("%d %s", visibleItems, itemName)
-
-	if m.filterState == Filtering {
-		// Filter results
-		if visibleItems == 0 {
-			status = m.Styles.StatusEmpty.Render("Nothing matched")
-		} else {
-			status = itemsDisplay
-		}
-	} else if len(m.items) == 0 {
-		// Not filtering: no items.
-		status = m.Styles.StatusEmpty.Render("No " + m.itemNamePlural)
-	} else {
-		// Normal
-		filtered := m.FilterState() == FilterApplied
-
-		if filtered {
-			f := strings.TrimSpace(m.FilterInput.Value())
-			f = ansi.Truncate(f, 10, "…")
-			status += fmt.Sprintf("“%s” ", f)
-		}
-
-		status += itemsDisplay
-	}
-
-	numFiltered := totalItems - visibleItems
-	if numFiltered > 0 {
-		status += m.Styles.DividerDot.String()
-		status += m.Styles.StatusBarFilterCount.Render(fmt.Sprintf("%d filtered", numFiltered))
+	if m.pendingRemoval != nil {
+		return m.Styles.StatusBar.Render(m.removalPromptView())
 	}
 
+	width := m.width - m.Styles.StatusBar.GetHorizontalFrameSize()
+	status := layoutStatus(m.statusSegmentsView(m.statusLeft), m.statusSegmentsView(m.statusRight), width)
 	return m.Styles.StatusBar.Render(status)
 }
 
@@ -1213,15 +1909,29 @@ func (m Model) populatedView() string {
 	var b strings.Builder
 
 	// Empty states
-	if len(items) == 0 {
-		if m.filterState == Filtering {
+	if len(items) == 0 && m.sections == nil {
+		if m.filterState == Filtering || m.fetching {
 			return ""
 		}
 		return m.Styles.NoItems.Render("No " + m.itemNamePlural + ".")
 	}
 
+	if m.scrollMode {
+		return m.scrollView()
+	}
+
+	if m.gridCellWidth > 0 {
+		start, end := m.pageBounds(m.Paginator.Page)
+		return m.gridView(items, start, end)
+	}
+
+	if m.sections != nil || m.pageStarts != nil {
+		start, end := m.pageBounds(m.Paginator.Page)
+		return m.itemsView(items, start, end)
+	}
+
 	if len(items) > 0 {
-		start, end := m.Paginator.GetSliceBounds(len(items))
+		start, end := m.pageBounds(m.Paginator.Page)
 		docs := items[start:end]
 
 		for i, item := range docs {
@@ -1236,7 +1946,7 @@ func (m Model) populatedView() string {
 	// then we need to add some newlines to fill up the space where items would
 	// have been.
 	itemsOnPage := m.Paginator.ItemsOnPage(len(items))
-	if itemsOnPage < m.Paginator.PerPage {
+	if itemsOnPage < m.Paginator.PerPage && m.pageStarts == nil {
 		n := (m.Paginator.PerPage - itemsOnPage) * (m.delegate.Height() + m.delegate.Spacing())
 		if len(items) == 0 {
 			n -= m.delegate.Height() - 1
@@ -1247,38 +1957,53 @@ func (m Model) populatedView() string {
 	return b.String()
 }
 
-func (m Model) helpView() string {
-	return m.Styles.HelpStyle.Render(m.Help.View(m))
-}
-
-func (m Model) spinnerView() string {
-	return m.spinner.View()
-}
+// itemsView renders the given range of items, along with the headers of the
+// sections they belong to. Pages only overflow when they hold a single item
+// taller than the page, in which case the bottom of the item is cut off so
+// that the rest of the list stays in place.
+func (m Model) itemsView(items []Item, start, end int) string {
+	var (
+		b    strings.Builder
+		gap  = strings.Repeat("\n", m.delegate.Spacing()+1)
+		rows int
+	)
 
-func filterItems(m Model) tea.Cmd {
-	return func() tea.Msg {
-		if m.FilterInput.Value() == "" || m.filterState == Unfiltered {
-			return FilterMatchesMsg(m.itemsAsFilterItems()) // return nothing
+	writeRow := func(render func()) {
+		if rows > 0 {
+			b.WriteString(gap)
 		}
-
-		items := m.items
-		targets := make([]string, len(items))
-
-		for i, t := range items {
-			targets[i] = t.FilterValue()
+		render()
+		rows++
+	}
+	writeHeaders := func(sections []groupSection) {
+		for _, s := range sections {
+			writeRow(func() { b.WriteString(m.groupHeaderView(s)) })
 		}
+	}
 
-		filterMatches := []filteredItem{}
-		for _, r := range m.Filter(m.FilterInput.Value(), targets) {
-			filterMatches = append(filterMatches, filteredItem{
-				index:   r.Index,
-				item:    items[r.Index],
-				matches: r.MatchedIndexes,
-			})
+	for i := start; i < end; i++ {
+		if m.sections != nil {
+			writeHeaders(m.headersBefore(i, start))
 		}
+		writeRow(func() { m.delegate.Render(&b, m, i, items[i]) })
+	}
+	if m.sections != nil && end == len(items) {
+		writeHeaders(m.trailingSections())
+	}
 
-		return FilterMatchesMsg(filterMatches)
+	lines := strings.Split(b.String(), "\n")
+	if len(lines) > m.itemsHeight {
+		lines = lines[:max(1, m.itemsHeight)]
 	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) helpView() string {
+	return m.Styles.HelpStyle.Render(m.Help.View(m))
+}
+
+func (m Model) spinnerView() string {
+	return m.spinner.View()
 }
 
 func insertItemIntoSlice(items []Item, item Item, index int) []Item {
@@ -1307,13 +2032,21 @@ func removeItemFromSlice(i []Item, index int) []Item {
 	return i[:len(i)-1]
 }
 
-func removeFilterMatchFromSlice(i []filteredItem, index int) []filteredItem {
-	if index >= len(i) {
-		return i // noop
+// removeFilterMatch removes the match for the item at the given index in the
+// unfiltered list of items, if any, and shifts the indexes of the items
+// after it.
+func removeFilterMatch(matches filteredItems, index int) filteredItems {
+	kept := matches[:0]
+	for _, fi := range matches {
+		if fi.index == index {
+			continue
+		}
+		if fi.index > index {
+			fi.index--
+		}
+		kept = append(kept, fi)
 	}
-	copy(i[index:], i[index+1:])
-	i[len(i)-1] = filteredItem{}
-	return i[:len(i)-1]
+	return kept
 }
 
 func countEnabledBindings(groups [][]key.Binding) (agg int) {
@@ -1333,3 +2066,10 @@ func max(a, b int) int {
 	}
 	return b
 }
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -1,13 +1,22 @@
 package list
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
 )
 
 type item string
@@ -53,7 +62,7 @@ func TestStatusBarWithoutItems(t *testing.T) {
 }
 
 func TestCustomStatusBarItemName(t *testing.T) {
-	list := New([]Item{item("foo"), item("bar")}, itemDelegate{}, 10, 10)
+	list := New([]Item{item("foo"), item("bar")}, itemDelegate{}, 20, 10)
 	list.SetStatusBarItemName("connection", "connections")
 
 	expected := "2 connections"
@@ -135,3 +144,1627 @@ func TestSetFilterState(t *testing.T) {
 		t.Fatalf("Error: expected view to contain '%s'", expected)
 	}
 }
+
+func TestMultiSelect(t *testing.T) {
+	tc := []Item{item("foo"), item("bar"), item("baz"), item("qux")}
+
+	list := New(tc, itemDelegate{}, 10, 10)
+	list.SetMultiSelect(true)
+
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyShiftDown})
+	expected := []int{0, 1}
+	if !reflect.DeepEqual(list.SelectedIndexes(), expected) {
+		t.Fatalf("Error: expected marked indexes %v, got %v", expected, list.SelectedIndexes())
+	}
+
+	// Marks refer to global indexes, whatever the filter.
+	list.SetFilterText("ba")
+	list.MarkAll()
+	expected = []int{0, 1, 2}
+	if !reflect.DeepEqual(list.SelectedIndexes(), expected) {
+		t.Fatalf("Error: expected marked indexes %v, got %v", expected, list.SelectedIndexes())
+	}
+	if !list.IsMarked(1) {
+		t.Fatal("Error: expected baz to be marked")
+	}
+	list.ResetFilter()
+
+	list.InsertItem(0, item("new"))
+	list.RemoveItem(2)
+	expectedItems := []Item{item("foo"), item("baz")}
+	if !reflect.DeepEqual(list.SelectedItems(), expectedItems) {
+		t.Fatalf("Error: expected marked items %v, got %v", expectedItems, list.SelectedItems())
+	}
+
+	list.UnmarkAll()
+	if len(list.SelectedItems()) != 0 {
+		t.Fatalf("Error: expected no marked items, got %v", list.SelectedItems())
+	}
+}
+
+type fakeSource struct {
+	items []Item
+	total int
+}
+
+func (s fakeSource) Fetch(ctx context.Context, offset, limit int) ([]Item, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	end := offset + limit
+	if end > len(s.items) {
+		end = len(s.items)
+	}
+	return s.items[offset:end], s.total, nil
+}
+
+// runCmd runs the given command and returns the messages it produces,
+// expanding batches.
+func runCmd(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		var msgs []tea.Msg
+		for _, c := range msg {
+			msgs = append(msgs, runCmd(c)...)
+		}
+		return msgs
+	case nil:
+		return nil
+	default:
+		return []tea.Msg{msg}
+	}
+}
+
+// fetchAll feeds fetched items into the list until it stops fetching.
+func fetchAll(m Model, cmd tea.Cmd) Model {
+	for cmd != nil {
+		var cmds []tea.Cmd
+		for _, msg := range runCmd(cmd) {
+			if _, ok := msg.(itemsFetchedMsg); !ok {
+				continue
+			}
+			var c tea.Cmd
+			m, c = m.Update(msg)
+			cmds = append(cmds, c)
+		}
+		cmd = tea.Batch(cmds...)
+	}
+	return m
+}
+
+func TestItemSource(t *testing.T) {
+	var items []Item
+	for i := 0; i < 100; i++ {
+		items = append(items, item(fmt.Sprintf("item %d", i)))
+	}
+
+	list := New(nil, itemDelegate{}, 20, 10)
+	list.SetShowHelp(false)
+	list.FetchSize = 10
+
+	stale := list.SetItemSource(fakeSource{items: []Item{item("stale")}, total: 1})
+	list = fetchAll(list, list.SetItemSource(fakeSource{items: items, total: -1}))
+	list = fetchAll(list, stale)
+
+	if n := len(list.Items()); n != 10 {
+		t.Fatalf("Error: expected 10 items to be loaded, got %d", n)
+	}
+	if list.Items()[0] != items[0] {
+		t.Fatalf("Error: expected items from the current source, got %v", list.Items()[0])
+	}
+	if !strings.Contains(list.statusView(), "10+ items") {
+		t.Fatalf("Error: expected status to show an unknown total, got %q", list.statusView())
+	}
+
+	// Moving to the last page loads more items.
+	list, cmd := list.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	if !list.Loading() {
+		t.Fatal("Error: expected the list to be loading")
+	}
+	list = fetchAll(list, cmd)
+	if n := len(list.Items()); n != 20 {
+		t.Fatalf("Error: expected 20 items to be loaded, got %d", n)
+	}
+
+	list = fetchAll(list, list.SetItemSource(fakeSource{items: items[:15], total: 15}))
+	for i := 0; i < 3; i++ {
+		var cmd tea.Cmd
+		list, cmd = list.Update(tea.KeyMsg{Type: tea.KeyEnd})
+		list = fetchAll(list, cmd)
+	}
+	if n := len(list.Items()); n != 15 {
+		t.Fatalf("Error: expected 15 items to be loaded, got %d", n)
+	}
+	if !strings.Contains(list.statusView(), "15 items") {
+		t.Fatalf("Error: expected status to show all items, got %q", list.statusView())
+	}
+}
+
+func TestFilterGenerations(t *testing.T) {
+	tc := []Item{item("foo"), item("bar"), item("baz"), item("qux"), item("bat")}
+
+	list := New(tc, itemDelegate{}, 10, 10)
+	list.FilterChunkSize = 2
+	list.FilterInput.Cursor.SetMode(cursor.CursorStatic)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+
+	list, staleCmd := list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	list, cmd := list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+
+	// Results are streamed chunk by chunk.
+	chunks := 0
+	for cmd != nil {
+		var msg filterResultMsg
+		for _, m := range runCmd(cmd) {
+			if m, ok := m.(filterResultMsg); ok {
+				msg = m
+			}
+		}
+		list, cmd = list.Update(msg)
+		chunks++
+	}
+	if chunks != 3 {
+		t.Fatalf("Error: expected 3 chunks, got %d", chunks)
+	}
+
+	// Results from an older run are dropped.
+	for _, msg := range runCmd(staleCmd) {
+		list, _ = list.Update(msg)
+	}
+
+	expected := []Item{item("bar"), item("baz"), item("bat")}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected %v, got %v", expected, list.VisibleItems())
+	}
+}
+
+func TestFilterChunksRanked(t *testing.T) {
+	tc := []Item{item("axxxxb"), item("cab"), item("ab")}
+
+	list := New(tc, itemDelegate{}, 10, 10)
+	list.FilterChunkSize = 1
+	list.FilterInput.Cursor.SetMode(cursor.CursorStatic)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	list, cmd := list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ab")})
+	for cmd != nil {
+		var msg filterResultMsg
+		for _, m := range runCmd(cmd) {
+			if m, ok := m.(filterResultMsg); ok {
+				msg = m
+			}
+		}
+		list, cmd = list.Update(msg)
+
+		// Untagged matches are ignored while a run is in progress.
+		if cmd != nil {
+			list, _ = list.Update(FilterMatchesMsg(nil))
+		}
+	}
+
+	// Better matches found in later chunks rank above worse ones found
+	// earlier.
+	expected := []Item{item("ab"), item("axxxxb"), item("cab")}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected %v, got %v", expected, list.VisibleItems())
+	}
+}
+
+func TestFilterRemoveDuringRun(t *testing.T) {
+	tc := []Item{item("a1"), item("a0"), item("b"), item("c"), item("a3"), item("a4")}
+
+	list := New(tc, itemDelegate{}, 10, 10)
+	list.FilterChunkSize = 2
+	list.FilterInput.Cursor.SetMode(cursor.CursorStatic)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	list, cmd := list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	for _, msg := range runCmd(cmd) {
+		list, cmd = list.Update(msg)
+	}
+
+	// Removing an item while later chunks are being filtered doesn't
+	// disturb them, and their results are superseded.
+	list.RemoveItem(0)
+	for cmd != nil {
+		var next tea.Cmd
+		for _, msg := range runCmd(cmd) {
+			list, next = list.Update(msg)
+		}
+		cmd = next
+	}
+	expected := []Item{item("a0"), item("a3"), item("a4")}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected %v, got %v", expected, list.VisibleItems())
+	}
+
+	// Results computed before a removal aren't applied after it.
+	tc = []Item{item("a0"), item("b1"), item("a2"), item("a3")}
+	list = New(tc, itemDelegate{}, 10, 10)
+	list.FilterInput.Cursor.SetMode(cursor.CursorStatic)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	list, cmd = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	msgs := runCmd(cmd)
+	list.RemoveItem(0)
+	for _, msg := range msgs {
+		list, _ = list.Update(msg)
+	}
+	expected = []Item{item("a2"), item("a3")}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected %v, got %v", expected, list.VisibleItems())
+	}
+	if got := list.globalIndexOf(0); got != 1 {
+		t.Fatalf("Error: expected a2 to be at index 1, got %d", got)
+	}
+}
+
+func TestFilterDebounce(t *testing.T) {
+	list := New([]Item{item("foo"), item("bar")}, itemDelegate{}, 10, 10)
+	list.FilterDebounce = time.Hour
+	list.FilterInput.Cursor.SetMode(cursor.CursorStatic)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	gen := list.filterGen
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+
+	// A debounce from before the last keystroke doesn't start a run.
+	if _, cmd := list.Update(filterDebounceMsg{id: list.id, gen: gen}); cmd != nil {
+		t.Fatal("Error: expected stale debounce to be ignored")
+	}
+
+	list, cmd := list.Update(filterDebounceMsg{id: list.id, gen: list.filterGen})
+	for _, msg := range runCmd(cmd) {
+		list, _ = list.Update(msg)
+	}
+	expected := []Item{item("foo")}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected %v, got %v", expected, list.VisibleItems())
+	}
+
+	// Accepting a filter before its run has started still notices that it
+	// matches nothing, and clears it.
+	list.ResetFilter()
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("zzz")})
+	gen = list.filterGen
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if list.FilterState() != Unfiltered || len(list.VisibleItems()) != 2 {
+		t.Fatalf("Error: expected the filter to be cleared, got %v with %v", list.FilterState(), list.VisibleItems())
+	}
+	if _, cmd := list.Update(filterDebounceMsg{id: list.id, gen: gen}); cmd != nil {
+		t.Fatal("Error: expected the debounce to be dropped after accepting")
+	}
+}
+
+func benchmarkItems(n int) []Item {
+	items := make([]Item, n)
+	for i := range items {
+		items[i] = item(fmt.Sprintf("item %d lorem ipsum dolor sit amet", i))
+	}
+	return items
+}
+
+func BenchmarkFilter(b *testing.B) {
+	items := benchmarkItems(100_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matchItems(filterValues(DefaultFilter), "im9d", items, 0)
+	}
+}
+
+func BenchmarkFilterChunked(b *testing.B) {
+	items := benchmarkItems(100_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cmd := filterChunk(context.Background(), 0, 0, filterValues(DefaultFilter), "im9d", items, 0, 10_000)
+		for cmd != nil {
+			cmd = cmd().(filterResultMsg).next
+		}
+	}
+}
+
+func BenchmarkFilterTyping(b *testing.B) {
+	list := New(benchmarkItems(100_000), itemDelegate{}, 80, 24)
+	list.FilterChunkSize = 10_000
+	list.FilterInput.Cursor.SetMode(cursor.CursorStatic)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("im9")})
+
+	// keystroke sends the given key and runs the filter to completion.
+	keystroke := func(msg tea.KeyMsg) {
+		var cmd tea.Cmd
+		list, cmd = list.Update(msg)
+		for cmd != nil {
+			var result tea.Msg
+			for _, msg := range runCmd(cmd) {
+				if msg, ok := msg.(filterResultMsg); ok {
+					result = msg
+				}
+			}
+			if result == nil {
+				break
+			}
+			list, cmd = list.Update(result)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		keystroke(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+		keystroke(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+}
+
+func BenchmarkScrollToEnd(b *testing.B) {
+	list := New(benchmarkItems(100_000), plainDelegate{}, 80, 24)
+	list.SetScrollMode(true)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		list.Select(len(list.Items()) - 1)
+		list.Select(0)
+	}
+}
+
+type issue struct {
+	title, status, label string
+}
+
+func (i issue) FilterValue() string { return i.title }
+
+func (i issue) FilterField(name string) (string, bool) {
+	switch name {
+	case "status":
+		return i.status, true
+	case "label":
+		return i.label, true
+	}
+	return "", false
+}
+
+func TestParseQuery(t *testing.T) {
+	q := ParseQuery(`status:open -label:wontfix "exact phrase" ^prefix suffix$ 'word !^neg`)
+	expected := Query{
+		{Field: "status", Text: "open"},
+		{Field: "label", Text: "wontfix", Negate: true},
+		{Text: "exact phrase", Kind: MatchExact},
+		{Text: "prefix", Kind: MatchPrefix},
+		{Text: "suffix", Kind: MatchSuffix},
+		{Text: "word", Kind: MatchExact},
+		{Text: "neg", Kind: MatchPrefix, Negate: true},
+	}
+	if !reflect.DeepEqual(q, expected) {
+		t.Fatalf("Error: expected %+v, got %+v", expected, q)
+	}
+}
+
+func TestFieldQueryFilter(t *testing.T) {
+	tc := []Item{
+		issue{"Crash on startup", "open", "bug"},
+		issue{"Crash when idle", "open", "wontfix"},
+		issue{"Crash in tests", "closed", "bug"},
+		issue{"Slow startup", "open", "perf"},
+	}
+
+	list := New(tc, itemDelegate{}, 10, 10)
+	list.ItemFilter = FieldQueryFilter
+	list.SetFilterText(`status:open -label:wontfix ^crash`)
+
+	expected := []Item{tc[0]}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected %v, got %v", expected, list.VisibleItems())
+	}
+	if matches := list.MatchesForItem(0); !reflect.DeepEqual(matches, []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("Error: expected the prefix to be highlighted, got %v", matches)
+	}
+
+	list.Filter = QueryFilter
+	list.ItemFilter = nil
+	list.SetFilterText(`"on start" !idle`)
+	expected = []Item{tc[0]}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected %v, got %v", expected, list.VisibleItems())
+	}
+
+	// QueryFilter only sees FilterValues, so field terms never match.
+	list.SetFilterText(`status:open crash`)
+	if n := len(list.VisibleItems()); n != 0 {
+		t.Fatalf("Error: expected field terms not to match, got %d items", n)
+	}
+}
+
+func TestSortOrders(t *testing.T) {
+	tc := []Item{item("bar"), item("foo"), item("baz"), item("abc")}
+
+	list := New(tc, itemDelegate{}, 40, 10)
+	list.SetSortOrders(
+		SortOrder{Name: "added"},
+		SortOrder{Name: "name", Less: func(a, b Item) bool {
+			return a.FilterValue() < b.FilterValue()
+		}},
+	)
+
+	list.Select(1)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	expected := []Item{item("abc"), item("bar"), item("baz"), item("foo")}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected %v, got %v", expected, list.VisibleItems())
+	}
+	if list.SelectedItem() != item("foo") || list.GlobalIndex() != 1 {
+		t.Fatalf("Error: expected foo to stay selected, got %v", list.SelectedItem())
+	}
+	if !strings.Contains(list.titleView(), "name") {
+		t.Fatal("Error: expected the title to show the sort order")
+	}
+
+	// Looking up the selection doesn't copy the sorted items.
+	if n := testing.AllocsPerRun(10, func() { list.SelectedItem() }); n != 0 {
+		t.Fatalf("Error: expected SelectedItem not to allocate, got %v allocations", n)
+	}
+
+	// Filtered items stay in rank order unless SortFilterResults is set.
+	list.SetFilterText("ba")
+	ranked := list.VisibleItems()
+	list.SortFilterResults = true
+	list.SetFilterText("ba")
+	expected = []Item{item("bar"), item("baz")}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected %v, got %v", expected, list.VisibleItems())
+	}
+	list.SortFilterResults = false
+	list.SetFilterText("ba")
+	if !reflect.DeepEqual(list.VisibleItems(), ranked) {
+		t.Fatalf("Error: expected rank order %v, got %v", ranked, list.VisibleItems())
+	}
+
+	list.ResetFilter()
+	list.CycleSortOrder()
+	if !reflect.DeepEqual(list.VisibleItems(), tc) {
+		t.Fatalf("Error: expected %v, got %v", tc, list.VisibleItems())
+	}
+}
+
+type task struct {
+	name, project string
+}
+
+func (t task) FilterValue() string { return t.name }
+func (t task) Group() string       { return t.project }
+
+func TestGroupedItems(t *testing.T) {
+	tc := []Item{
+		task{"write docs", "docs"},
+		task{"fix crash", "core"},
+		task{"proofread", "docs"},
+		task{"add tests", "core"},
+	}
+
+	list := New(tc, itemDelegate{}, 40, 20)
+	list.SetShowHelp(false)
+
+	expected := []Item{tc[0], tc[2], tc[1], tc[3]}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected %v, got %v", expected, list.VisibleItems())
+	}
+
+	view := list.View()
+	if !strings.Contains(view, "docs") || strings.Index(view, "docs") > strings.Index(view, "core") {
+		t.Fatalf("Error: expected a header for each group, got:\n%s", view)
+	}
+
+	// The cursor moves from the last item of one group to the first item of
+	// the next, skipping the header.
+	list.Select(1)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if list.SelectedItem() != tc[1] || list.GlobalIndex() != 1 {
+		t.Fatalf("Error: expected %v to be selected, got %v", tc[1], list.SelectedItem())
+	}
+
+	list.Select(0)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+	if !list.GroupCollapsed("docs") {
+		t.Fatal("Error: expected docs to be collapsed")
+	}
+	expected = []Item{tc[1], tc[3]}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected %v, got %v", expected, list.VisibleItems())
+	}
+	if list.SelectedItem() != tc[1] {
+		t.Fatalf("Error: expected %v to be selected, got %v", tc[1], list.SelectedItem())
+	}
+	if !strings.Contains(list.View(), "docs") {
+		t.Fatal("Error: expected the header of the collapsed group to be shown")
+	}
+	if s := StatusFilteredCount(list); s != "" {
+		t.Fatalf("Error: expected collapsed items not to count as filtered, got %q", s)
+	}
+
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'+'}})
+	if list.GroupCollapsed("docs") || list.SelectedItem() != tc[1] {
+		t.Fatalf("Error: expected docs to be expanded with %v still selected", tc[1])
+	}
+
+	// Toggling acts on the collapsed header next to the selected item, or
+	// on the group of the selected item.
+	list.SetGroupCollapsed("docs", true)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if list.GroupCollapsed("docs") || list.SelectedItem() != tc[0] {
+		t.Fatalf("Error: expected docs to be expanded with %v selected, got %v", tc[0], list.SelectedItem())
+	}
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if !list.GroupCollapsed("docs") {
+		t.Fatal("Error: expected docs to be collapsed again")
+	}
+	list.ExpandAllGroups()
+	list.Select(2)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if list.GroupCollapsed("core") || list.SelectedItem() != tc[1] {
+		t.Fatalf("Error: expected core to be expanded with %v selected, got %v", tc[1], list.SelectedItem())
+	}
+
+	// Groups without matches are hidden.
+	list.SetFilterText("tests")
+	if view := list.View(); strings.Contains(view, "docs") {
+		t.Fatalf("Error: expected the docs group to be hidden, got:\n%s", view)
+	}
+
+	// Headers take up rows, so fewer items fit on a page.
+	list = New(tc, itemDelegate{}, 40, 10)
+	list.SetShowHelp(false)
+	if list.Paginator.TotalPages != 2 {
+		t.Fatalf("Error: expected 2 pages, got %d", list.Paginator.TotalPages)
+	}
+	list.Select(3)
+	if list.Paginator.Page != 1 || list.Cursor() != 1 {
+		t.Fatalf("Error: expected the last item on page 1, got page %d cursor %d", list.Paginator.Page, list.Cursor())
+	}
+}
+
+// tallDelegate renders each item on as many lines as it has characters.
+type tallDelegate struct{ itemDelegate }
+
+func (d tallDelegate) HeightFor(m Model, index int, listItem Item) int {
+	return len(listItem.FilterValue())
+}
+
+func (d tallDelegate) Render(w io.Writer, m Model, index int, listItem Item) {
+	v := listItem.FilterValue()
+	fmt.Fprint(w, strings.TrimSuffix(strings.Repeat(v+"\n", len(v)), "\n"))
+}
+
+func TestVariableHeightItems(t *testing.T) {
+	tc := []Item{item("aaa"), item("bbb"), item("c"), item("dd"), item("eeee"), item("f")}
+
+	const height = 12
+	list := New(tc, tallDelegate{}, 20, height)
+	list.SetShowHelp(false)
+
+	var pages [][]Item
+	for page := 0; page < list.Paginator.TotalPages; page++ {
+		list.Paginator.Page = page
+		view := list.View()
+		if n := len(strings.Split(view, "\n")); n != height {
+			t.Fatalf("Error: expected page %d to be %d lines, got %d:\n%s", page, height, n, view)
+		}
+
+		lines := make(map[string]int)
+		for _, l := range strings.Split(view, "\n") {
+			lines[strings.TrimSpace(l)]++
+		}
+		start, end := list.pageBounds(page)
+		for _, i := range tc[start:end] {
+			if v := i.FilterValue(); lines[v] != len(v) {
+				t.Fatalf("Error: expected all of %q on page %d, got:\n%s", v, page, view)
+			}
+		}
+		pages = append(pages, tc[start:end])
+	}
+
+	expected := [][]Item{{tc[0], tc[1]}, {tc[2], tc[3]}, {tc[4], tc[5]}}
+	if !reflect.DeepEqual(pages, expected) {
+		t.Fatalf("Error: expected pages %v, got %v", expected, pages)
+	}
+
+	list.Select(3)
+	if list.Paginator.Page != 1 || list.Cursor() != 1 || list.SelectedItem() != tc[3] {
+		t.Fatalf("Error: expected dd selected on page 1, got page %d cursor %d", list.Paginator.Page, list.Cursor())
+	}
+	list.CursorDown()
+	if list.Paginator.Page != 2 || list.SelectedItem() != tc[4] {
+		t.Fatalf("Error: expected eeee selected on page 2, got page %d", list.Paginator.Page)
+	}
+
+	// An item taller than a page gets a page of its own and is clipped.
+	tall := item(strings.Repeat("g", height+3))
+	list.SetItems([]Item{tc[2], tall, tc[5]})
+	expected = [][]Item{{tc[2]}, {tall}, {tc[5]}}
+	pages = nil
+	for page := 0; page < list.Paginator.TotalPages; page++ {
+		start, end := list.pageBounds(page)
+		pages = append(pages, list.VisibleItems()[start:end])
+	}
+	if !reflect.DeepEqual(pages, expected) {
+		t.Fatalf("Error: expected pages %v, got %v", expected, pages)
+	}
+	list.Select(1)
+	if n := len(strings.Split(list.View(), "\n")); n != height {
+		t.Fatalf("Error: expected the tall item to be clipped to %d lines, got %d:\n%s", height, n, list.View())
+	}
+}
+
+// plainDelegate renders each item's FilterValue on a single line.
+type plainDelegate struct{ itemDelegate }
+
+func (d plainDelegate) Render(w io.Writer, m Model, index int, listItem Item) {
+	fmt.Fprint(w, listItem.FilterValue())
+}
+
+func TestScrollMode(t *testing.T) {
+	var tc []Item
+	for i := 0; i < 20; i++ {
+		tc = append(tc, item(fmt.Sprintf("item %02d", i)))
+	}
+
+	const height = 9
+	list := New(tc, plainDelegate{}, 20, height)
+	list.SetShowHelp(false)
+	list.SetScrollMode(true)
+	list.SetShowScrollbar(true)
+	list.ScrollOff = 1
+
+	// Title and status bar take up four lines, leaving room for five items.
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	for i := 0; i < 3; i++ {
+		list, _ = list.Update(down)
+	}
+	if list.Index() != 3 || list.scrollOffset != 0 {
+		t.Fatalf("Error: expected no scrolling yet, got index %d offset %d", list.Index(), list.scrollOffset)
+	}
+	list, _ = list.Update(down)
+	if list.Index() != 4 || list.scrollOffset != 1 || list.Cursor() != 3 {
+		t.Fatalf("Error: expected to scroll by one item, got index %d offset %d", list.Index(), list.scrollOffset)
+	}
+
+	view := list.View()
+	if n := len(strings.Split(view, "\n")); n != height {
+		t.Fatalf("Error: expected %d lines, got %d:\n%s", height, n, view)
+	}
+	if strings.Contains(view, "item 00") || !strings.Contains(view, "item 05") || strings.Contains(view, "item 06") {
+		t.Fatalf("Error: expected items 1 to 5 in view, got:\n%s", view)
+	}
+	if !strings.Contains(view, list.Styles.ScrollbarThumb.String()) || strings.Contains(view, bullet) {
+		t.Fatalf("Error: expected a scrollbar instead of pagination, got:\n%s", view)
+	}
+
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	if list.Index() != 9 || list.scrollOffset != 6 {
+		t.Fatalf("Error: expected to scroll down by five items, got index %d offset %d", list.Index(), list.scrollOffset)
+	}
+
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	if list.Index() != 19 || list.scrollOffset != 15 {
+		t.Fatalf("Error: expected the last items in view, got index %d offset %d", list.Index(), list.scrollOffset)
+	}
+
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyUp})
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyUp})
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if list.Index() != 16 || list.scrollOffset != 15 {
+		t.Fatalf("Error: expected no scrolling yet, got index %d offset %d", list.Index(), list.scrollOffset)
+	}
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if list.Index() != 15 || list.scrollOffset != 14 {
+		t.Fatalf("Error: expected to scroll up by one item, got index %d offset %d", list.Index(), list.scrollOffset)
+	}
+
+	list.SetScrollMode(false)
+	if list.Index() != 15 || list.Paginator.TotalPages < 2 {
+		t.Fatalf("Error: expected pagination to be restored, got index %d and %d pages", list.Index(), list.Paginator.TotalPages)
+	}
+
+	// Going to the end of a filter that matched nothing, and clearing it,
+	// selects the first item.
+	list.SetScrollMode(true)
+	list.SetFilterText("zzz")
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if list.scrollOffset != 0 || list.SelectedItem() == nil {
+		t.Fatalf("Error: expected the first items in view, got index %d offset %d", list.Index(), list.scrollOffset)
+	}
+}
+
+func TestMouse(t *testing.T) {
+	var tc []Item
+	for i := 0; i < 20; i++ {
+		tc = append(tc, item(fmt.Sprintf("item %02d", i)))
+	}
+
+	list := New(tc, plainDelegate{}, 20, 12)
+	list.SetShowHelp(false)
+	list.YPosition = 3
+
+	click := func(x, y int) tea.MouseMsg {
+		return tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft}
+	}
+
+	// The title and status bar take up four lines.
+	hit, ok := list.HitTest(5, 3+4+2)
+	if !ok || hit.Index != 2 || hit.X != 5 || hit.Y != 0 {
+		t.Fatalf("Error: expected to hit item 2, got %+v", hit)
+	}
+	if _, ok := list.HitTest(5, 3+1); ok {
+		t.Fatal("Error: expected no item under the title")
+	}
+
+	var cmd tea.Cmd
+	list, cmd = list.Update(click(5, 3+4+2))
+	if list.Index() != 2 {
+		t.Fatalf("Error: expected item 2 to be selected, got %d", list.Index())
+	}
+	for _, msg := range runCmd(cmd) {
+		if _, ok := msg.(ItemActivatedMsg); ok {
+			t.Fatal("Error: expected a single click not to activate the item")
+		}
+	}
+
+	list, cmd = list.Update(click(5, 3+4+2))
+	var activated bool
+	for _, msg := range runCmd(cmd) {
+		if msg, ok := msg.(ItemActivatedMsg); ok {
+			activated = msg.Index == 2 && msg.Item == tc[2]
+		}
+	}
+	if !activated {
+		t.Fatal("Error: expected a double-click to activate item 2")
+	}
+
+	list, _ = list.Update(tea.MouseMsg{X: 5, Y: 8, Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	if list.Index() != 3 {
+		t.Fatalf("Error: expected the wheel to move to item 3, got %d", list.Index())
+	}
+
+	// In scroll mode the wheel scrolls the view, dragging the cursor along.
+	list.SetScrollMode(true)
+	list.Select(0)
+	for i := 0; i < 3; i++ {
+		list, _ = list.Update(tea.MouseMsg{X: 5, Y: 8, Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	}
+	if list.scrollOffset != 3 || list.Index() != 3 {
+		t.Fatalf("Error: expected to scroll by three items, got offset %d index %d", list.scrollOffset, list.Index())
+	}
+	hit, ok = list.HitTest(0, 3+4)
+	if !ok || hit.Index != 3 {
+		t.Fatalf("Error: expected to hit item 3, got %+v", hit)
+	}
+}
+
+func TestReorder(t *testing.T) {
+	tc := []Item{item("a"), item("b"), item("c"), item("d")}
+
+	list := New(append([]Item{}, tc...), itemDelegate{}, 10, 10)
+	list.SetReorderable(true)
+	list.SetMultiSelect(true)
+	list.ToggleMark()
+
+	var cmd tea.Cmd
+	list, cmd = list.Update(tea.KeyMsg{Type: tea.KeyDown, Alt: true})
+	expected := []Item{item("b"), item("a"), item("c"), item("d")}
+	if !reflect.DeepEqual(list.Items(), expected) {
+		t.Fatalf("Error: expected %v, got %v", expected, list.Items())
+	}
+	if list.SelectedItem() != item("a") || !reflect.DeepEqual(list.SelectedIndexes(), []int{1}) {
+		t.Fatalf("Error: expected a to stay selected and marked, got %v", list.SelectedItem())
+	}
+	var reordered ItemsReorderedMsg
+	for _, msg := range runCmd(cmd) {
+		if msg, ok := msg.(ItemsReorderedMsg); ok {
+			reordered = msg
+		}
+	}
+	if reordered != (ItemsReorderedMsg{From: 0, To: 1, Item: item("a")}) {
+		t.Fatalf("Error: expected a reorder message, got %+v", reordered)
+	}
+
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}, Alt: true})
+	expected = []Item{item("b"), item("c"), item("d"), item("a")}
+	if !reflect.DeepEqual(list.Items(), expected) || list.Index() != 3 {
+		t.Fatalf("Error: expected %v with a selected, got %v", expected, list.Items())
+	}
+
+	// Items can't be moved while a filter is applied.
+	list.SetFilterText("a")
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyUp, Alt: true})
+	if !reflect.DeepEqual(list.Items(), expected) {
+		t.Fatalf("Error: expected no change while filtered, got %v", list.Items())
+	}
+	list.ResetFilter()
+
+	// Nothing is moved when the selected item has been removed.
+	list.Select(3)
+	list.RemoveItem(3)
+	expected = expected[:3]
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyUp, Alt: true})
+	if !reflect.DeepEqual(list.Items(), expected) {
+		t.Fatalf("Error: expected no change without a selection, got %v", list.Items())
+	}
+
+	// Grouped items move within their group.
+	tasks := []Item{task{"a", "x"}, task{"b", "y"}, task{"c", "x"}}
+	list = New(append([]Item{}, tasks...), itemDelegate{}, 10, 20)
+	list.SetReorderable(true)
+	list.Select(1)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyDown, Alt: true})
+	expected = []Item{tasks[0], tasks[1], tasks[2]}
+	if !reflect.DeepEqual(list.Items(), expected) {
+		t.Fatalf("Error: expected no move past the end of the group, got %v", list.Items())
+	}
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyUp, Alt: true})
+	expected = []Item{tasks[2], tasks[0], tasks[1]}
+	if !reflect.DeepEqual(list.Items(), expected) || list.SelectedItem() != tasks[2] {
+		t.Fatalf("Error: expected %v, got %v", expected, list.Items())
+	}
+}
+
+type node struct {
+	name     string
+	children []Item
+}
+
+func (n node) FilterValue() string { return n.name }
+func (n node) Children() []Item    { return n.children }
+
+func TestTree(t *testing.T) {
+	util := node{"util", []Item{node{name: "a.go"}, node{name: "b.go"}}}
+	src := node{"src", []Item{node{name: "main.go"}, util}}
+	readme := node{name: "README"}
+
+	list := New(nil, plainDelegate{}, 40, 20)
+	list.SetShowHelp(false)
+	list.SetTree([]Item{src, readme})
+
+	expected := []Item{src, readme}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected only the roots, got %v", list.VisibleItems())
+	}
+
+	shiftRight := tea.KeyMsg{Type: tea.KeyShiftRight}
+	list, _ = list.Update(shiftRight)
+	expected = []Item{src, node{name: "main.go"}, util, readme}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected src to be expanded, got %v", list.VisibleItems())
+	}
+
+	// Expanding an open node moves to its first child.
+	list, _ = list.Update(shiftRight)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyDown})
+	list, _ = list.Update(shiftRight)
+	if list.SelectedItem().FilterValue() != "util" || list.GlobalIndex() != 2 || len(list.VisibleItems()) != 6 {
+		t.Fatalf("Error: expected util to be expanded, got %v", list.VisibleItems())
+	}
+	if p := list.TreePrefix(1); !strings.HasPrefix(p, "├─") {
+		t.Fatalf("Error: expected a branch guide for main.go, got %q", p)
+	}
+	if p := list.TreePrefix(3); !strings.HasPrefix(p, "   ├─") {
+		t.Fatalf("Error: expected a branch guide for a.go, got %q", p)
+	}
+	if p := list.TreePrefix(4); !strings.HasPrefix(p, "   └─") {
+		t.Fatalf("Error: expected a closing guide for b.go, got %q", p)
+	}
+
+	list.Select(4)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if list.SelectedItem().FilterValue() != "util" {
+		t.Fatalf("Error: expected to go to util, got %v", list.SelectedItem())
+	}
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'H'}})
+	if list.NodeExpanded(2) || len(list.VisibleItems()) != 4 {
+		t.Fatalf("Error: expected util to be collapsed, got %v", list.VisibleItems())
+	}
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'H'}})
+	if list.SelectedItem().FilterValue() != "src" {
+		t.Fatalf("Error: expected to go to src, got %v", list.SelectedItem())
+	}
+	if s := StatusFilteredCount(list); s != "" {
+		t.Fatalf("Error: expected collapsed nodes not to count as filtered, got %q", s)
+	}
+
+	// Filtering shows the ancestors of matching nodes, even collapsed ones.
+	list.SetFilterText("b.go")
+	expected = []Item{src, util, node{name: "b.go"}}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected b.go and its ancestors, got %v", list.VisibleItems())
+	}
+	if s := StatusFilteredCount(list); !strings.Contains(s, "5 filtered") {
+		t.Fatalf("Error: expected the nodes that don't match to count as filtered, got %q", s)
+	}
+	if len(list.MatchesForItem(0)) != 0 || len(list.MatchesForItem(2)) == 0 {
+		t.Fatal("Error: expected only b.go to be highlighted")
+	}
+	if list.TreeDepth(2) != 2 {
+		t.Fatalf("Error: expected b.go at depth 2, got %d", list.TreeDepth(2))
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	tc := []Item{item("bar"), item("foo"), item("baz"), item("abc"), item("bat")}
+	orders := []SortOrder{
+		{Name: "added"},
+		{Name: "name", Less: func(a, b Item) bool {
+			return a.FilterValue() < b.FilterValue()
+		}},
+	}
+
+	list := New(append([]Item{}, tc...), itemDelegate{}, 40, 20)
+	list.SetSortOrders(orders...)
+	list.SetSortOrder(1)
+	list.SortFilterResults = true
+	list.SetFilterText("ba")
+	list.Select(2)
+	if list.SelectedItem() != item("baz") {
+		t.Fatalf("Error: expected baz to be selected, got %v", list.SelectedItem())
+	}
+
+	b, err := json.Marshal(list.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s Snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"filterState":"filter applied"`) {
+		t.Fatalf("Error: expected the filter state by name, got %s", b)
+	}
+
+	restored := New(append([]Item{}, tc...), itemDelegate{}, 40, 20)
+	restored.SetSortOrders(orders...)
+	restored.SortFilterResults = true
+	restored.Restore(s)
+	if restored.FilterState() != FilterApplied || restored.FilterValue() != "ba" {
+		t.Fatalf("Error: expected the filter to be applied, got %v %q", restored.FilterState(), restored.FilterValue())
+	}
+	if o, _ := restored.SortOrder(); o.Name != "name" {
+		t.Fatalf("Error: expected the name sort order, got %q", o.Name)
+	}
+	if !reflect.DeepEqual(restored.VisibleItems(), list.VisibleItems()) || restored.SelectedItem() != item("baz") {
+		t.Fatalf("Error: expected baz to be selected in %v, got %v", list.VisibleItems(), restored.SelectedItem())
+	}
+
+	// Without the selected item, the cursor goes back as close as possible
+	// to where it was.
+	restored = New([]Item{item("bar"), item("bat"), item("foo")}, itemDelegate{}, 40, 20)
+	restored.Restore(s)
+	if restored.Index() != 1 || restored.SelectedItem() != item("bat") {
+		t.Fatalf("Error: expected the cursor on the last item, got %d", restored.Index())
+	}
+
+	// Unknown filter states are ignored.
+	restored.Restore(Snapshot{Filter: "ba", FilterState: "sorted"})
+	if restored.FilterState() != Unfiltered {
+		t.Fatalf("Error: expected an unknown filter state to be ignored, got %v", restored.FilterState())
+	}
+
+	// In scroll mode the cursor goes back to the same item position even
+	// though it's relative to how far the list is scrolled.
+	var many []Item
+	for i := 0; i < 30; i++ {
+		many = append(many, item(fmt.Sprintf("item %02d", i)))
+	}
+	list = New(many, itemDelegate{}, 40, 10)
+	list.SetScrollMode(true)
+	list.Select(20)
+	s = list.Snapshot()
+	restored = New(many[:25], itemDelegate{}, 40, 10)
+	restored.SetScrollMode(true)
+	s.Index = -1 // as if the item was gone
+	restored.Restore(s)
+	if restored.Index() != 20 {
+		t.Fatalf("Error: expected the cursor on item 20, got %d", restored.Index())
+	}
+}
+
+func TestPreview(t *testing.T) {
+	tc := []Item{item("foo"), item("bar")}
+	preview := func(i Item) string {
+		var lines []string
+		for n := 0; n < 20; n++ {
+			lines = append(lines, fmt.Sprintf("%s line %d", i.FilterValue(), n))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	list := New(tc, plainDelegate{}, 40, 12)
+	list.SetShowHelp(false)
+	list.SetPreviewFunc(preview)
+	if list.Width() != 40 || list.width != 20 || list.Preview.Height != 12 {
+		t.Fatalf("Error: expected the preview to take half the width, got list %d preview %dx%d", list.width, list.Preview.Width, list.Preview.Height)
+	}
+
+	view := list.View()
+	lines := strings.Split(view, "\n")
+	if len(lines) != 12 || !strings.Contains(lines[0], "foo line 0") {
+		t.Fatalf("Error: expected the preview of foo beside the list, got:\n%s", view)
+	}
+	for _, line := range lines {
+		if w := ansi.StringWidth(line); w > 40 {
+			t.Fatalf("Error: expected lines no wider than 40, got %d:\n%s", w, view)
+		}
+	}
+
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if list.Preview.YOffset != 2 || !strings.Contains(list.View(), "foo line 2") || strings.Contains(list.View(), "foo line 1\n") {
+		t.Fatalf("Error: expected the preview to scroll by two lines, got offset %d", list.Preview.YOffset)
+	}
+
+	// Selecting another item scrolls the preview back to the top.
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if list.Preview.YOffset != 0 || !strings.Contains(list.View(), "bar line 0") {
+		t.Fatalf("Error: expected the preview of bar from the top, got offset %d", list.Preview.YOffset)
+	}
+
+	// So does selecting or replacing items outside of Update.
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	list.Select(0)
+	if list.Preview.YOffset != 0 {
+		t.Fatalf("Error: expected Select to scroll the preview to the top, got offset %d", list.Preview.YOffset)
+	}
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	list.SetItems([]Item{item("baz"), item("qux")})
+	if list.Preview.YOffset != 0 || !strings.Contains(list.View(), "baz line 0") {
+		t.Fatalf("Error: expected the preview of baz from the top, got offset %d", list.Preview.YOffset)
+	}
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	list.CursorDown()
+	if !strings.Contains(list.View(), "qux line 0") {
+		t.Fatalf("Error: expected the preview of qux from the top, got:\n%s", list.View())
+	}
+
+	list.SetPreviewPosition(PreviewBelow)
+	list.SetPreviewRatio(0.25)
+	list.SetSize(30, 20)
+	if list.width != 30 || list.height != 15 || list.Preview.Height != 5 {
+		t.Fatalf("Error: expected the preview below the list, got list %dx%d preview %dx%d", list.width, list.height, list.Preview.Width, list.Preview.Height)
+	}
+	if n := len(strings.Split(list.View(), "\n")); n != 20 {
+		t.Fatalf("Error: expected 20 lines, got %d", n)
+	}
+}
+
+type describedItem struct{ title, desc string }
+
+func (i describedItem) Title() string       { return i.title }
+func (i describedItem) Description() string { return i.desc }
+func (i describedItem) FilterValue() string { return i.title }
+
+func TestHighlightMatches(t *testing.T) {
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(termenv.ANSI)
+	bold := r.NewStyle().Bold(true)
+
+	for _, tc := range []struct {
+		s       string
+		matches []int
+		want    string
+	}{
+		{"héllo", []int{1, 2}, "h\x1b[1mél\x1b[0mlo"},
+		{"日本語", []int{1}, "日\x1b[1m本\x1b[0m語"},
+		// Styled input keeps its style outside of matches.
+		{"\x1b[31mabc\x1b[0m", []int{1}, "\x1b[31ma\x1b[1mb\x1b[0m\x1b[31mc\x1b[0m"},
+		// Characters made of several runes are highlighted whole.
+		{"👍🏽!", []int{0}, "\x1b[1m👍🏽\x1b[0m!"},
+		{"abc", nil, "abc"},
+	} {
+		if got := HighlightMatches(tc.s, tc.matches, bold); got != tc.want {
+			t.Errorf("Error: HighlightMatches(%q, %v) = %q, want %q", tc.s, tc.matches, got, tc.want)
+		}
+	}
+
+	// The default filter reports rune indices, not byte offsets.
+	if ranks := DefaultFilter("本", []string{"日本語"}); len(ranks) != 1 || !reflect.DeepEqual(ranks[0].MatchedIndexes, []int{1}) {
+		t.Fatalf("Error: expected a match at rune 1, got %v", ranks)
+	}
+
+	// Matches in descriptions are carried through to the delegate.
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI)
+	defer lipgloss.SetColorProfile(profile)
+
+	tc := []Item{describedItem{"alpha", "first\nsecond line"}, describedItem{"beta", "other"}}
+	list := New(tc, NewDefaultDelegate(), 40, 20)
+	list.ItemFilter = func(term string, items []Item) []Rank {
+		return []Rank{{Index: 0, DescriptionMatchedIndexes: []int{6, 7}}}
+	}
+	list.SetFilterText("se")
+	if got := list.DescriptionMatchesForItem(0); !reflect.DeepEqual(got, []int{6, 7}) {
+		t.Fatalf("Error: expected description matches, got %v", got)
+	}
+
+	var b strings.Builder
+	d := NewDefaultDelegate()
+	d.SetHeight(3)
+	d.Render(&b, list, 0, tc[0])
+	underlined := d.Styles.SelectedDesc.Inline(true).Inherit(d.Styles.FilterMatch).Render("se")
+	if !strings.Contains(b.String(), underlined) || strings.Contains(b.String(), "fir"+underlined) {
+		t.Fatalf("Error: expected \"se\" to be highlighted on the second line, got %q", b.String())
+	}
+}
+
+func TestMultiFieldFilter(t *testing.T) {
+	tc := []Item{
+		describedItem{"apple", "a red fruit"},
+		describedItem{"banana", "yellow"},
+		describedItem{"red pepper", "vegetable"},
+	}
+
+	tests := []struct {
+		term    string
+		indexes []int
+		fields  []map[string][]int
+	}{
+		// Matches in titles rank above those in descriptions.
+		{"red", []int{2, 0}, []map[string][]int{
+			{TitleField: {0, 1, 2}},
+			{DescriptionField: {2, 3, 4}},
+		}},
+		// Each word can match a different field.
+		{"apple fruit", []int{0}, []map[string][]int{
+			{TitleField: {0, 1, 2, 3, 4}, DescriptionField: {6, 7, 8, 9, 10}},
+		}},
+		// But every word has to match.
+		{"apple yellow", nil, nil},
+	}
+	for _, tt := range tests {
+		ranks := DefaultItemFilter(tt.term, tc)
+		var (
+			indexes []int
+			fields  []map[string][]int
+		)
+		for _, r := range ranks {
+			indexes = append(indexes, r.Index)
+			fields = append(fields, r.FieldMatchedIndexes)
+		}
+		if !reflect.DeepEqual(indexes, tt.indexes) || !reflect.DeepEqual(fields, tt.fields) {
+			t.Fatalf("Error: expected %q to match %v in %v, got %v in %v", tt.term, tt.indexes, tt.fields, indexes, fields)
+		}
+	}
+
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI)
+	defer lipgloss.SetColorProfile(profile)
+
+	d := NewDefaultDelegate()
+	list := New(tc, d, 40, 20)
+	list.ItemFilter = DefaultItemFilter
+	list.SetFilterText("fruit")
+	if got := list.FieldMatchesForItem(0, DescriptionField); !reflect.DeepEqual(got, []int{6, 7, 8, 9, 10}) {
+		t.Fatalf("Error: expected description matches, got %v", got)
+	}
+
+	// Delegates built on MatchesForItem and DescriptionMatchesForItem get
+	// the matches in titles and descriptions too.
+	if got := list.DescriptionMatchesForItem(0); !reflect.DeepEqual(got, []int{6, 7, 8, 9, 10}) {
+		t.Fatalf("Error: expected description matches, got %v", got)
+	}
+
+	// Weighted scores keep their fractional part.
+	if ranks := DefaultItemFilter("fruit", tc); len(ranks) != 1 || ranks[0].Score != 99.5 {
+		t.Fatalf("Error: expected a score of 99.5, got %v", ranks)
+	}
+
+	var b strings.Builder
+	d.Render(&b, list, 0, tc[0])
+	highlighted := d.Styles.SelectedDesc.Inline(true).Inherit(d.Styles.FilterMatch).Render("fruit")
+	if !strings.Contains(b.String(), highlighted) {
+		t.Fatalf("Error: expected \"fruit\" to be highlighted in the description, got %q", b.String())
+	}
+
+	list.SetFilterText("app")
+	if got := list.MatchesForItem(0); !reflect.DeepEqual(got, []int{0, 1, 2}) {
+		t.Fatalf("Error: expected title matches, got %v", got)
+	}
+}
+
+type file string
+
+func (f file) FilterValue() string { return string(f) }
+func (f file) Actions() []Action {
+	return []Action{
+		{Name: "open", Key: key.NewBinding(key.WithKeys("o"))},
+		{Name: "delete"},
+	}
+}
+
+type actionDelegate struct{ plainDelegate }
+
+func (d actionDelegate) Actions(item Item) []Action {
+	return []Action{{Name: "copy", Key: key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy name"))}}
+}
+
+func TestActions(t *testing.T) {
+	tc := []Item{file("a.txt"), file("b.txt")}
+	list := New(tc, actionDelegate{}, 40, 20)
+
+	actionOf := func(cmd tea.Cmd) (ActionMsg, bool) {
+		for _, msg := range runCmd(cmd) {
+			if msg, ok := msg.(ActionMsg); ok {
+				return msg, true
+			}
+		}
+		return ActionMsg{}, false
+	}
+
+	names := func(kb []key.Binding) (s []string) {
+		for _, b := range kb {
+			s = append(s, b.Help().Desc)
+		}
+		return s
+	}
+	if help := strings.Join(names(list.ShortHelp()), ","); !strings.Contains(help, "copy name,open,actions") {
+		t.Fatalf("Error: expected the item's actions in the help, got %s", help)
+	}
+
+	// An action's key runs it directly.
+	list.Select(1)
+	_, cmd := list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	if msg, ok := actionOf(cmd); !ok || msg.Action.Name != "open" || msg.Item != tc[1] || msg.Index != 1 {
+		t.Fatalf("Error: expected to open b.txt, got %+v", msg)
+	}
+
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'.'}})
+	if !list.ActionMenuOpen() {
+		t.Fatal("Error: expected the action menu to be open")
+	}
+	view := list.View()
+	if !strings.Contains(view, "copy") || !strings.Contains(view, "delete") || !strings.Contains(view, "b.txt") {
+		t.Fatalf("Error: expected the menu below b.txt, got:\n%s", view)
+	}
+	if strings.Index(view, "b.txt") > strings.Index(view, "copy") {
+		t.Fatalf("Error: expected the menu below the selected item, got:\n%s", view)
+	}
+
+	// The cursor keys move through the menu rather than the list.
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyUp})
+	list, cmd = list.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg, ok := actionOf(cmd); !ok || msg.Action.Name != "delete" || msg.Item != tc[1] {
+		t.Fatalf("Error: expected to delete b.txt, got %+v", msg)
+	}
+	if list.ActionMenuOpen() || list.Index() != 1 {
+		t.Fatalf("Error: expected the menu to close with b.txt still selected, got %d", list.Index())
+	}
+
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'.'}})
+	list, cmd = list.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if list.ActionMenuOpen() || cmd != nil && cmd() != nil {
+		t.Fatal("Error: expected escape to close the menu without quitting")
+	}
+
+	// The list's own keybindings take precedence over action keys.
+	list = New(tc, clashingDelegate{}, 40, 20)
+	list, cmd = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	if _, ok := actionOf(cmd); ok || list.Index() != 1 {
+		t.Fatalf("Error: expected j to move the cursor down, got index %d", list.Index())
+	}
+}
+
+type clashingDelegate struct{ plainDelegate }
+
+func (d clashingDelegate) Actions(item Item) []Action {
+	return []Action{{Name: "jump", Key: key.NewBinding(key.WithKeys("j"))}}
+}
+
+func TestRemoveWithUndo(t *testing.T) {
+	tc := []Item{item("a"), item("b"), item("c")}
+	list := New(append([]Item{}, tc...), itemDelegate{}, 40, 20)
+	list.SetRemovable(true)
+	list.StatusMessageLifetime = 10 * time.Millisecond
+
+	press := func(k tea.KeyMsg) []tea.Msg {
+		var cmd tea.Cmd
+		list, cmd = list.Update(k)
+		return runCmd(cmd)
+	}
+	keyX := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}}
+	keyY := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}}
+	keyN := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}}
+	undo := tea.KeyMsg{Type: tea.KeyCtrlZ}
+
+	list.Select(1)
+	press(keyX)
+	if !list.RemovalPending() || !strings.Contains(list.statusView(), "Remove “b”?") {
+		t.Fatalf("Error: expected a confirmation prompt, got %q", list.statusView())
+	}
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(keyN)
+	if list.RemovalPending() || len(list.Items()) != 3 || list.Index() != 1 {
+		t.Fatalf("Error: expected nothing to change, got %v", list.Items())
+	}
+
+	press(keyX)
+	msgs := press(keyY)
+	expected := []Item{item("a"), item("c")}
+	if !reflect.DeepEqual(list.Items(), expected) || !strings.Contains(list.titleView(), "Removed “b”") {
+		t.Fatalf("Error: expected b to be removed, got %v", list.Items())
+	}
+	list, _ = list.Update(undo)
+	if !reflect.DeepEqual(list.Items(), tc) || list.SelectedItem() != item("b") {
+		t.Fatalf("Error: expected b to be put back and selected, got %v", list.Items())
+	}
+
+	// Once undone, the removal isn't committed when its time is up.
+	for _, msg := range msgs {
+		var cmd tea.Cmd
+		list, cmd = list.Update(msg)
+		if len(runCmd(cmd)) > 0 {
+			t.Fatal("Error: expected no message for an undone removal")
+		}
+	}
+
+	press(keyX)
+	var removed []ItemRemovedMsg
+	for _, msg := range press(keyY) {
+		var cmd tea.Cmd
+		list, cmd = list.Update(msg)
+		for _, msg := range runCmd(cmd) {
+			if msg, ok := msg.(ItemRemovedMsg); ok {
+				removed = append(removed, msg)
+			}
+		}
+	}
+	if !reflect.DeepEqual(removed, []ItemRemovedMsg{{Index: 1, Item: item("b")}}) {
+		t.Fatalf("Error: expected the removal of b to be committed, got %v", removed)
+	}
+	list, _ = list.Update(undo)
+	if !reflect.DeepEqual(list.Items(), expected) {
+		t.Fatalf("Error: expected a committed removal not to be undone, got %v", list.Items())
+	}
+
+	// Another status message doesn't hide the undo hint.
+	press(keyX)
+	press(keyY)
+	list.NewStatusMessage("Saved")
+	if view := list.titleView(); !strings.Contains(view, "Removed “c”") || !strings.Contains(view, "Saved") {
+		t.Fatalf("Error: expected the undo hint next to the status message, got %q", view)
+	}
+	list, _ = list.Update(undo)
+	if !reflect.DeepEqual(list.Items(), expected) {
+		t.Fatalf("Error: expected c to be put back, got %v", list.Items())
+	}
+
+	// Removing the last item selects the one before it.
+	list = New(append([]Item{}, tc...), itemDelegate{}, 40, 20)
+	list.SetRemovable(true)
+	list.StatusMessageLifetime = 10 * time.Millisecond
+	list.Select(2)
+	press(keyX)
+	press(keyY)
+	if list.SelectedItem() != item("b") {
+		t.Fatalf("Error: expected b to be selected, got %v", list.SelectedItem())
+	}
+
+	// While filtered, the item put back is selected once it's filtered.
+	list = New([]Item{item("foo"), item("bar"), item("baz")}, itemDelegate{}, 40, 20)
+	list.SetRemovable(true)
+	list.SetFilterText("ba")
+	list.ConfirmRemoveItem(2)
+	list, _ = list.Update(keyY)
+	list, cmd := list.Update(undo)
+	for _, msg := range runCmd(cmd) {
+		list, _ = list.Update(msg)
+	}
+	if list.SelectedItem() != item("baz") {
+		t.Fatalf("Error: expected baz to be put back and selected, got %v", list.SelectedItem())
+	}
+
+	// While filtered, RemoveItem takes an index in the unfiltered items.
+	list = New([]Item{item("foo"), item("bar"), item("baz")}, itemDelegate{}, 40, 20)
+	list.SetFilterText("ba")
+	list.RemoveItem(2)
+	if !reflect.DeepEqual(list.VisibleItems(), []Item{item("bar")}) || list.GlobalIndex() != 1 {
+		t.Fatalf("Error: expected baz to be removed, got %v", list.VisibleItems())
+	}
+}
+
+func TestGridLayout(t *testing.T) {
+	var tc []Item
+	for _, s := range strings.Split("abcdefghij", "") {
+		tc = append(tc, item(s))
+	}
+
+	list := New(tc, itemDelegate{}, 20, 2)
+	list.SetShowTitle(false)
+	list.SetShowFilter(false)
+	list.SetShowStatusBar(false)
+	list.SetShowPagination(false)
+	list.SetShowHelp(false)
+	list.SetGridLayout(6)
+
+	if list.GridColumns() != 3 || list.Paginator.PerPage != 6 || list.Paginator.TotalPages != 2 {
+		t.Fatalf("Error: expected 2 pages of 2 rows of 3 cells, got %d columns, %d per page and %d pages",
+			list.GridColumns(), list.Paginator.PerPage, list.Paginator.TotalPages)
+	}
+	lines := strings.Split(list.View(), "\n")
+	if lines[0] != "  1. a  2. b  3. c" || lines[1] != "  4. d  5. e  6. f" {
+		t.Fatalf("Error: expected items in rows of 3 cells, got %q", lines)
+	}
+
+	steps := []struct {
+		key   tea.KeyType
+		index int
+	}{
+		{tea.KeyRight, 1},
+		{tea.KeyDown, 4},
+		{tea.KeyDown, 7}, // onto the next page
+		{tea.KeyDown, 9}, // onto the shorter last row
+		{tea.KeyDown, 9},
+		{tea.KeyUp, 6},
+		{tea.KeyLeft, 5},
+		{tea.KeyUp, 2},
+		{tea.KeyUp, 2},
+	}
+	for _, s := range steps {
+		list, _ = list.Update(tea.KeyMsg{Type: s.key})
+		if list.Index() != s.index {
+			t.Fatalf("Error: expected %v to select item %d, got %d", s.key, s.index, list.Index())
+		}
+	}
+	if list.Paginator.Page != 0 {
+		t.Fatalf("Error: expected to be back on the first page, got page %d", list.Paginator.Page)
+	}
+
+	hit, ok := list.HitTest(13, 1)
+	if !ok || hit.Index != 5 || hit.X != 1 || hit.Width != 6 {
+		t.Fatalf("Error: expected a hit on the third cell of the second row, got %+v", hit)
+	}
+	if _, ok := list.HitTest(19, 0); ok {
+		t.Fatal("Error: expected no hit past the last column")
+	}
+
+	// The keys that move the cursor sideways are left out of the help for
+	// changing pages.
+	helpKeys := func() (keys []string) {
+		for _, b := range list.FullHelp()[0] {
+			keys = append(keys, b.Help().Key)
+		}
+		return keys
+	}
+	if keys := strings.Join(helpKeys(), " "); !strings.Contains(keys, "←/h →/l pgdn pgup") {
+		t.Fatalf("Error: expected the page help without the cursor keys, got %q", keys)
+	}
+
+	list.SetGridLayout(0)
+	if keys := strings.Join(helpKeys(), " "); !strings.Contains(keys, "→/l/pgdn") {
+		t.Fatalf("Error: expected the full page help, got %q", keys)
+	}
+	if list.GridColumns() != 1 || list.Paginator.PerPage != 2 || list.Index() != 2 {
+		t.Fatalf("Error: expected a vertical list, got %d columns and %d per page",
+			list.GridColumns(), list.Paginator.PerPage)
+	}
+}
+
+func TestFrecency(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryHistory()
+	_ = store.Record("bar", now.Add(-30*24*time.Hour))
+	_ = store.Record("baz", now.Add(-time.Hour))
+
+	f, err := NewFrecency(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Score(item("foo")) != 0 || f.Score(item("baz")) <= f.Score(item("bar")) {
+		t.Fatalf("Error: expected recent choices to score higher, got %v and %v",
+			f.Score(item("bar")), f.Score(item("baz")))
+	}
+
+	list := New([]Item{item("foo"), item("bar"), item("baz")}, itemDelegate{}, 10, 10)
+	list.SetFrecency(f)
+	list.SetFilterText("ba")
+	expected := []Item{item("baz"), item("bar")}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected the recent choice first, got %v", list.VisibleItems())
+	}
+
+	// Choosing bar often enough puts it first.
+	list.Select(1)
+	for i := 0; i < 2; i++ {
+		if err := list.RecordSelection(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list.SetFilterText("ba")
+	expected = []Item{item("bar"), item("baz")}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected the frequent choice first, got %v", list.VisibleItems())
+	}
+
+	// Frecency ranks matches across chunks too.
+	list = New([]Item{item("foo"), item("baz"), item("bar")}, itemDelegate{}, 10, 10)
+	list.SetFrecency(f)
+	list.FilterChunkSize = 1
+	list.FilterInput.Cursor.SetMode(cursor.CursorStatic)
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	list, cmd := list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ba")})
+	for cmd != nil {
+		var msg filterResultMsg
+		for _, m := range runCmd(cmd) {
+			if m, ok := m.(filterResultMsg); ok {
+				msg = m
+			}
+		}
+		list, cmd = list.Update(msg)
+	}
+	expected = []Item{item("bar"), item("baz")}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected the frequent choice first across chunks, got %v", list.VisibleItems())
+	}
+
+	// The history is ranked when unfiltered with the frecency sort order.
+	list.ResetFilter()
+	list.SetSortOrders(f.SortOrder())
+	expected = []Item{item("bar"), item("baz"), item("foo")}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("Error: expected items in frecency order, got %v", list.VisibleItems())
+	}
+
+	// The file store keeps the history across loads.
+	path := t.TempDir() + "/history/list.json"
+	if h, err := NewFileHistory(path).Load(); err != nil || len(h) != 0 {
+		t.Fatalf("Error: expected an empty history from a missing file, got %v, %v", h, err)
+	}
+	file, _ := NewFrecency(NewFileHistory(path))
+	if err := file.Record(item("foo")); err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewFileHistory(path).Load()
+	if err != nil || h["foo"].Count != 1 {
+		t.Fatalf("Error: expected the choice to be persisted, got %v, %v", h, err)
+	}
+}
+
+func TestStatusBarSegments(t *testing.T) {
+	tc := []Item{item("foo"), item("bar"), item("baz")}
+	list := New(tc, itemDelegate{}, 40, 10)
+	list.SetFilterText("ba")
+	list.SetFilterState(FilterApplied)
+
+	// The default segments show the same status as always.
+	expected := "“ba” 2 items • 1 filtered"
+	if got := strings.TrimSpace(list.statusView()); got != expected {
+		t.Fatalf("Error: expected status %q, got %q", expected, got)
+	}
+
+	list.SetMultiSelect(true)
+	list.MarkAll()
+	list.SetSortOrders(SortOrder{Name: "name", Less: func(a, b Item) bool {
+		return a.FilterValue() < b.FilterValue()
+	}})
+	custom := func(Model) string { return "app" }
+	list.SetStatusBarSegments(
+		[]StatusSegment{StatusItemCount, StatusMarkedCount},
+		[]StatusSegment{StatusSortOrder, custom},
+	)
+	line := strings.Split(list.statusView(), "\n")[0]
+	if ansi.StringWidth(line) != 40 || !strings.HasPrefix(line, "  2 items • 2 marked") ||
+		!strings.HasSuffix(line, "⇅ name • app") {
+		t.Fatalf("Error: expected segments on the left and right, got %q", line)
+	}
+
+	// When there isn't enough room, the left side is truncated.
+	list.SetSize(22, 10)
+	line = strings.Split(list.statusView(), "\n")[0]
+	if line != "  2 item… ⇅ name • app" {
+		t.Fatalf("Error: expected the left side to be truncated, got %q", line)
+	}
+
+	// So are the default segments, with nothing on the right.
+	list.SetStatusBarSegments(DefaultStatusSegments(), nil)
+	list.SetSize(12, 10)
+	line = strings.Split(list.statusView(), "\n")[0]
+	if line != "  “ba” 2 it…" {
+		t.Fatalf("Error: expected the status to be truncated, got %q", line)
+	}
+}
@@ -0,0 +1,212 @@
+package list
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Two clicks on the same item within this interval make a double-click.
+const doubleClickInterval = 400 * time.Millisecond
+
+// ItemActivatedMsg is sent when an item is double-clicked.
+type ItemActivatedMsg struct {
+	// Index is the index of the item in the unfiltered list of items. This
+	// value can be used with SetItem.
+	Index int
+
+	Item Item
+}
+
+// ItemHit describes the item at a position in the list. See HitTest.
+type ItemHit struct {
+	// Index is the index of the item in the visible items, as with Index.
+	Index int
+
+	Item Item
+
+	// X and Y are the position relative to the item's top-left corner.
+	X, Y int
+
+	// Width and Height are the size of the item.
+	Width, Height int
+}
+
+// HitTest returns the item at the given terminal position, such as that of
+// a tea.MouseMsg, and false if there is no item there. XPosition and
+// YPosition are taken into account, as are the title, filter and status
+// bars and the height and spacing of items. Delegates can use it to handle
+// clicks on parts of an item.
+func (m Model) HitTest(x, y int) (ItemHit, bool) {
+	return m.hitTest(x-m.XPosition, y-m.YPosition)
+}
+
+// hitTest returns the item at the given position relative to the top-left
+// corner of the list.
+func (m Model) hitTest(x, y int) (ItemHit, bool) {
+	width := m.width
+	if m.scrollbarShown() {
+		width--
+	}
+	if x < 0 || x >= width {
+		return ItemHit{}, false
+	}
+
+	y -= m.itemsTop()
+	if y < 0 || y >= m.itemsHeight {
+		return ItemHit{}, false
+	}
+
+	var (
+		hit ItemHit
+		ok  bool
+	)
+	m.eachItemInView(func(index, top, height int) bool {
+		left, cellWidth := 0, width
+		if m.gridCellWidth > 0 {
+			left, cellWidth = m.gridColumnOf(index)*m.gridCellWidth, m.gridCellWidth
+		}
+		if y >= top && y < top+height && x >= left && x < left+cellWidth {
+			hit = ItemHit{
+				Index:  index,
+				Item:   m.VisibleItems()[index],
+				X:      x - left,
+				Y:      y - top,
+				Width:  cellWidth,
+				Height: height,
+			}
+			ok = true
+		}
+		return !ok
+	})
+	return hit, ok
+}
+
+// eachItemInView calls fn with the index of each item in view, the row it
+// starts at relative to the first item in view and its height, until fn
+// returns false. In grid layout, the items in a row of cells start at the
+// same row.
+func (m Model) eachItemInView(fn func(index, top, height int) bool) {
+	var (
+		items      = m.VisibleItems()
+		start, end = m.itemsInView()
+		spacing    = m.delegate.Spacing()
+		top        int
+	)
+	for i := start; i < end; i++ {
+		if m.sections != nil {
+			headerRows := lipgloss.Height(m.groupHeaderView(groupSection{})) + spacing
+			top += headerRows * len(m.headersBefore(i, start))
+		}
+		height := m.itemHeight(i, items[i])
+		if !fn(i, top, height) {
+			return
+		}
+		if m.gridCellWidth <= 0 || m.gridColumnOf(i) == m.GridColumns()-1 {
+			top += height + spacing
+		}
+	}
+}
+
+// handleMouse selects clicked items and scrolls with the mouse wheel.
+func (m *Model) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	if msg.Action != tea.MouseActionPress {
+		return nil
+	}
+
+	x, y := msg.X-m.XPosition, msg.Y-m.YPosition
+	if x < 0 || y < 0 || x >= m.width || y >= m.height {
+		return nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		if m.MouseWheelEnabled {
+			m.scrollWheel(-m.MouseWheelDelta)
+		}
+
+	case tea.MouseButtonWheelDown:
+		if m.MouseWheelEnabled {
+			m.scrollWheel(m.MouseWheelDelta)
+		}
+
+	case tea.MouseButtonLeft:
+		hit, ok := m.hitTest(x, y)
+		if !ok {
+			return nil
+		}
+		m.Select(hit.Index)
+
+		index, now := m.GlobalIndex(), time.Now()
+		if index == m.lastClickIndex && now.Sub(m.lastClick) <= doubleClickInterval {
+			m.lastClick = time.Time{}
+			return func() tea.Msg {
+				return ItemActivatedMsg{Index: index, Item: hit.Item}
+			}
+		}
+		m.lastClick, m.lastClickIndex = now, index
+	}
+	return nil
+}
+
+// scrollWheel scrolls by the given number of items. In scroll mode the
+// view scrolls and the cursor only moves to stay in view; otherwise the
+// cursor moves.
+func (m *Model) scrollWheel(n int) {
+	if !m.scrollMode {
+		for ; n < 0; n++ {
+			m.CursorUp()
+		}
+		for ; n > 0; n-- {
+			m.CursorDown()
+		}
+		return
+	}
+
+	items := m.VisibleItems()
+	if len(items) == 0 {
+		return
+	}
+
+	last := len(items) - 1
+	for last > 0 && m.scrollEnd(items, last-1) == len(items) {
+		last--
+	}
+	m.scrollOffset = max(0, min(last, m.scrollOffset+n))
+
+	// Keep the cursor in view, outside of the scroll-off margin.
+	start, end := m.itemsInView()
+	margin := min(max(0, m.ScrollOff), (end-start-1)/2) //nolint:gomnd
+	lo, hi := start+margin, end-1-margin
+	if start == 0 {
+		lo = 0
+	}
+	if end == len(items) {
+		hi = len(items) - 1
+	}
+	m.Select(max(lo, min(hi, m.Index())))
+}
+
+// itemsInView returns the range of visible items currently rendered.
+func (m Model) itemsInView() (start, end int) {
+	if m.scrollMode {
+		items := m.VisibleItems()
+		start = min(m.scrollOffset, len(items))
+		return start, m.scrollEnd(items, start)
+	}
+	return m.pageBounds(m.Paginator.Page)
+}
+
+// itemsTop returns the row the items start at, below the title and status
+// bars.
+func (m Model) itemsTop() int {
+	var top int
+	if m.showTitle || (m.showFilter && m.filteringEnabled) {
+		top += lipgloss.Height(m.titleView())
+	}
+	if m.showStatusBar {
+		top += lipgloss.Height(m.statusView())
+	}
+	return top
+}
@@ -0,0 +1,121 @@
+package list
+
+import "sort"
+
+// SetMultiSelect enables or disables marking multiple items. Disabling
+// multi-select unmarks all items.
+func (m *Model) SetMultiSelect(v bool) {
+	m.multiSelect = v
+	if !v {
+		m.marked = nil
+	}
+	m.updateKeybindings()
+}
+
+// MultiSelect returns whether marking multiple items is enabled.
+func (m Model) MultiSelect() bool {
+	return m.multiSelect
+}
+
+// IsMarked returns whether the item at the given index in the visible items
+// is marked.
+func (m Model) IsMarked(index int) bool {
+	if len(m.marked) == 0 {
+		return false
+	}
+	_, ok := m.marked[m.globalIndexOf(index)]
+	return ok
+}
+
+// ToggleMark marks or unmarks the selected item.
+func (m *Model) ToggleMark() {
+	if m.visibleCount() == 0 {
+		return
+	}
+	i := m.GlobalIndex()
+	if _, ok := m.marked[i]; ok {
+		delete(m.marked, i)
+		return
+	}
+	m.mark(i)
+}
+
+// MarkAll marks all visible items. If a filter is applied only the items
+// matching the filter are marked.
+func (m *Model) MarkAll() {
+	for i := range m.VisibleItems() {
+		m.mark(m.globalIndexOf(i))
+	}
+}
+
+// UnmarkAll unmarks all items, including ones hidden by a filter.
+func (m *Model) UnmarkAll() {
+	m.marked = nil
+}
+
+// SelectedItems returns the marked items in the order they appear in Items.
+// Items hidden by a filter are included.
+func (m Model) SelectedItems() []Item {
+	indexes := m.SelectedIndexes()
+	items := make([]Item, len(indexes))
+	for i, index := range indexes {
+		items[i] = m.items[index]
+	}
+	return items
+}
+
+// SelectedIndexes returns the indexes of the marked items, as they are stored
+// in the unfiltered list of items, in ascending order. These values can be
+// used with SetItem.
+func (m Model) SelectedIndexes() []int {
+	indexes := make([]int, 0, len(m.marked))
+	for i := range m.marked {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// mark marks the item at the given global index.
+func (m *Model) mark(index int) {
+	if m.marked == nil {
+		m.marked = make(map[int]struct{})
+	}
+	m.marked[index] = struct{}{}
+}
+
+// markRange marks the selected item, moves the cursor in the given direction
+// and marks the newly selected item.
+func (m *Model) markRange(up bool) {
+	if m.visibleCount() == 0 {
+		return
+	}
+	m.mark(m.GlobalIndex())
+	if up {
+		m.CursorUp()
+	} else {
+		m.CursorDown()
+	}
+	m.mark(m.GlobalIndex())
+}
+
+// shiftMarks moves marks at or after the given global index by delta, keeping
+// them on the same items when items are inserted or removed. When removing, a
+// mark on the removed item is dropped.
+func (m *Model) shiftMarks(index, delta int) {
+	if len(m.marked) == 0 {
+		return
+	}
+	marked := make(map[int]struct{}, len(m.marked))
+	for i := range m.marked {
+		switch {
+		case i < index:
+			marked[i] = struct{}{}
+		case delta < 0 && i < index-delta:
+			// The item was removed.
+		default:
+			marked[i+delta] = struct{}{}
+		}
+	}
+	m.marked = marked
+}
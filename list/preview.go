@@ -0,0 +1,139 @@
+package list
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The share of the list's size given to the preview pane by default.
+const defaultPreviewRatio = 0.5
+
+// PreviewFunc renders the preview of an item. See SetPreviewFunc.
+type PreviewFunc func(item Item) string
+
+// PreviewPosition is where the preview pane is placed relative to the items.
+type PreviewPosition int
+
+// Possible preview positions.
+const (
+	PreviewRight PreviewPosition = iota
+	PreviewBelow
+)
+
+// SetPreviewFunc shows a preview of the selected item next to the list,
+// rendered by the given function. The preview is shown in the Preview
+// viewport so that long previews can be scrolled with the preview
+// keybindings. Pass nil to hide the preview pane.
+func (m *Model) SetPreviewFunc(fn PreviewFunc) {
+	m.previewFunc = fn
+	m.setSize(m.totalWidth, m.totalHeight)
+	m.updateKeybindings()
+}
+
+// SetPreviewPosition sets where the preview pane is placed.
+func (m *Model) SetPreviewPosition(pos PreviewPosition) {
+	m.previewPosition = pos
+	m.setSize(m.totalWidth, m.totalHeight)
+}
+
+// PreviewPosition returns where the preview pane is placed.
+func (m Model) PreviewPosition() PreviewPosition {
+	return m.previewPosition
+}
+
+// SetPreviewRatio sets the share of the list's width, or of its height when
+// the preview is below the items, given to the preview pane. It must be
+// between 0 and 1; other values reset it to the default of a half.
+func (m *Model) SetPreviewRatio(ratio float64) {
+	if ratio <= 0 || ratio >= 1 {
+		ratio = defaultPreviewRatio
+	}
+	m.previewRatio = ratio
+	m.setSize(m.totalWidth, m.totalHeight)
+}
+
+// PreviewRatio returns the share of the list's size given to the preview
+// pane.
+func (m Model) PreviewRatio() float64 {
+	return m.previewRatio
+}
+
+// layoutPreview sizes the preview pane within the given size and returns
+// the size left for the rest of the list.
+func (m *Model) layoutPreview(width, height int) (int, int) {
+	if m.previewFunc == nil {
+		return width, height
+	}
+
+	frameWidth, frameHeight := m.Styles.Preview.GetFrameSize()
+	switch m.previewPosition {
+	case PreviewBelow:
+		size := int(float64(height) * m.previewRatio)
+		m.Preview.Width = max(0, width-frameWidth)
+		m.Preview.Height = max(0, size-frameHeight)
+		return width, height - size
+	default:
+		size := int(float64(width) * m.previewRatio)
+		m.Preview.Width = max(0, size-frameWidth)
+		m.Preview.Height = max(0, height-frameHeight)
+		return width - size, height
+	}
+}
+
+// previewContent renders the preview of the selected item.
+func (m Model) previewContent() string {
+	item := m.SelectedItem()
+	if m.previewFunc == nil || item == nil {
+		return ""
+	}
+	return m.previewFunc(item)
+}
+
+// scrollPreview scrolls the preview pane with the preview keybindings.
+func (m *Model) scrollPreview(msg tea.KeyMsg) {
+	m.updatePreview()
+	m.Preview.SetContent(m.previewContent())
+
+	switch {
+	case key.Matches(msg, m.KeyMap.PreviewUp):
+		m.Preview.LineUp(1)
+	case key.Matches(msg, m.KeyMap.PreviewDown):
+		m.Preview.LineDown(1)
+	case key.Matches(msg, m.KeyMap.PreviewHalfPageUp):
+		m.Preview.HalfViewUp()
+	case key.Matches(msg, m.KeyMap.PreviewHalfPageDown):
+		m.Preview.HalfViewDown()
+	}
+}
+
+// updatePreview scrolls the preview pane back to the top when another item
+// is selected. It's called by Select and after every Update, and
+// previewView shows the preview from the top in case the selection has
+// changed in between, such as with CursorDown.
+func (m *Model) updatePreview() {
+	if m.previewFunc == nil {
+		return
+	}
+	if i := m.GlobalIndex(); i != m.previewIndex {
+		m.previewIndex = i
+		m.Preview.SetYOffset(0)
+	}
+}
+
+// previewView renders the preview pane next to the given view of the rest
+// of the list.
+func (m Model) previewView(list string) string {
+	vp := m.Preview
+	vp.SetContent(m.previewContent())
+	if m.GlobalIndex() != m.previewIndex {
+		vp.SetYOffset(0)
+	}
+	pane := m.Styles.Preview.Render(vp.View())
+
+	if m.previewPosition == PreviewBelow {
+		return lipgloss.JoinVertical(lipgloss.Left, list, pane)
+	}
+	list = lipgloss.NewStyle().Width(m.width).Render(list)
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, pane)
+}
@@ -0,0 +1,347 @@
+package list
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// FieldedItem is an item with named fields that can be searched with field
+// terms in a query, such as status:open.
+//
+// A FilterFunc only sees each item's FilterValue, so field terms can only
+// match when the list filters with FieldQueryFilter set as its ItemFilter.
+// With QueryFilter set as its Filter, a query with a field term matches no
+// items, and negated field terms are ignored.
+type FieldedItem interface {
+	Item
+
+	// FilterField returns the value of the named field, and whether the item
+	// has such a field. Field names in queries are lowercased.
+	FilterField(name string) (string, bool)
+}
+
+// MatchKind describes how a query term is matched against a value.
+type MatchKind int
+
+// Available match kinds.
+const (
+	MatchFuzzy  MatchKind = iota // term
+	MatchExact                   // 'term or "some term"
+	MatchPrefix                  // ^term
+	MatchSuffix                  // term$
+	MatchEqual                   // ^term$
+)
+
+// QueryTerm is a single term of a Query.
+type QueryTerm struct {
+	// Field is the name of the field the term is matched against. If it's
+	// empty the term is matched against the item's FilterValue.
+	Field string
+
+	// Text is the text to match, without any operators.
+	Text string
+
+	// Kind is how Text is matched.
+	Kind MatchKind
+
+	// Negate inverts the term, so that it matches items that don't match
+	// Text.
+	Negate bool
+}
+
+// Query is a parsed filter query. An item matches a query if it matches all
+// of its terms.
+type Query []QueryTerm
+
+// ParseQuery parses a filter query in the style of fzf's extended search
+// mode. Terms are separated by spaces and matched case-insensitively:
+//
+//	foo       fuzzy match
+//	'foo      exact match
+//	"foo bar" exact match, including spaces
+//	^foo      prefix match
+//	foo$      suffix match
+//	^foo$     exact match of the whole value
+//	!foo      items that don't match foo; -foo also works
+//	key:foo   match foo against the field key of a FieldedItem
+//
+// Operators can be combined, as in -label:^wont.
+func ParseQuery(s string) Query {
+	var q Query
+	for _, tok := range tokenizeQuery(s) {
+		var t QueryTerm
+
+		if len(tok) > 1 && (tok[0] == '!' || tok[0] == '-') {
+			t.Negate = true
+			tok = tok[1:]
+		}
+
+		if i := strings.IndexRune(tok, ':'); i > 0 && i < len(tok)-1 && isFieldName(tok[:i]) {
+			t.Field = strings.ToLower(tok[:i])
+			tok = tok[i+1:]
+		}
+
+		switch {
+		case len(tok) > 1 && tok[0] == '"' && tok[len(tok)-1] == '"':
+			t.Kind = MatchExact
+			tok = tok[1 : len(tok)-1]
+		case len(tok) > 1 && tok[0] == '\'':
+			t.Kind = MatchExact
+			tok = tok[1:]
+		default:
+			prefix := len(tok) > 1 && tok[0] == '^'
+			if prefix {
+				tok = tok[1:]
+			}
+			suffix := len(tok) > 1 && tok[len(tok)-1] == '$'
+			if suffix {
+				tok = tok[:len(tok)-1]
+			}
+			switch {
+			case prefix && suffix:
+				t.Kind = MatchEqual
+			case prefix:
+				t.Kind = MatchPrefix
+			case suffix:
+				t.Kind = MatchSuffix
+			}
+		}
+
+		t.Text = tok
+		q = append(q, t)
+	}
+	return q
+}
+
+// Match returns whether the item matches the query, and the rune indices of
+// the item's FilterValue that were matched. Field terms only match items
+// implementing FieldedItem.
+func (q Query) Match(item Item) (matchedIndexes []int, ok bool) {
+	matchedIndexes, _, ok = q.match(item)
+	return matchedIndexes, ok
+}
+
+// match matches the query against an item, returning the matched rune
+// indices of its FilterValue and a score used to rank it.
+func (q Query) match(item Item) ([]int, int, bool) {
+	var (
+		indexes []int
+		score   int
+		value   = item.FilterValue()
+	)
+
+	for _, t := range q {
+		target := value
+		if t.Field != "" {
+			var ok bool
+			if f, isFielded := item.(FieldedItem); isFielded {
+				target, ok = f.FilterField(t.Field)
+			}
+			if !ok {
+				if t.Negate {
+					continue
+				}
+				return nil, 0, false
+			}
+		}
+
+		idx, s, ok := t.match(target)
+		if ok == t.Negate {
+			return nil, 0, false
+		}
+		if t.Negate {
+			continue
+		}
+		score += s
+		if t.Field == "" {
+			indexes = append(indexes, idx...)
+		}
+	}
+
+	return uniqueSorted(indexes), score, true
+}
+
+// match matches the term against s, returning the matched rune indices of s
+// and a score.
+func (t QueryTerm) match(s string) ([]int, int, bool) {
+	if t.Kind == MatchFuzzy {
+		matches := fuzzy.Find(t.Text, []string{s})
+		if len(matches) == 0 {
+			return nil, 0, false
+		}
+		return byteToRuneIndexes(s, matches[0].MatchedIndexes), matches[0].Score, true
+	}
+
+	var (
+		haystack = foldRunes(s)
+		needle   = foldRunes(t.Text)
+		start    = -1
+	)
+
+	switch t.Kind {
+	case MatchExact:
+		start = indexRunes(haystack, needle)
+	case MatchPrefix:
+		if hasPrefixRunes(haystack, needle) {
+			start = 0
+		}
+	case MatchSuffix:
+		if len(haystack) >= len(needle) && hasPrefixRunes(haystack[len(haystack)-len(needle):], needle) {
+			start = len(haystack) - len(needle)
+		}
+	case MatchEqual:
+		if len(haystack) == len(needle) && hasPrefixRunes(haystack, needle) {
+			start = 0
+		}
+	}
+	if start < 0 {
+		return nil, 0, false
+	}
+
+	indexes := make([]int, len(needle))
+	for i := range indexes {
+		indexes[i] = start + i
+	}
+	return indexes, 0, true
+}
+
+// QueryFilter is a FilterFunc that filters items with a query in the style of
+// fzf's extended search mode. See ParseQuery for the syntax, and FieldedItem
+// for field terms, which QueryFilter doesn't match.
+func QueryFilter(term string, targets []string) []Rank {
+	items := make([]Item, len(targets))
+	for i, t := range targets {
+		items[i] = stringItem(t)
+	}
+	return FieldQueryFilter(term, items)
+}
+
+// FieldQueryFilter is an ItemFilterFunc that filters items with a query in
+// the style of fzf's extended search mode, including field terms matched
+// against items implementing FieldedItem. See ParseQuery for the syntax.
+//
+// Results are sorted by fuzzy match score. Matched indexes refer to each
+// item's FilterValue.
+func FieldQueryFilter(term string, items []Item) []Rank {
+	q := ParseQuery(term)
+
+	var matches []Rank
+	for i, item := range items {
+		indexes, score, ok := q.match(item)
+		if !ok {
+			continue
+		}
+		matches = append(matches, Rank{Index: i, MatchedIndexes: indexes, Score: float64(score)})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// stringItem is an Item for plain filter values.
+type stringItem string
+
+func (s stringItem) FilterValue() string { return string(s) }
+
+// tokenizeQuery splits a query on whitespace, keeping quoted sections
+// together.
+func tokenizeQuery(s string) []string {
+	var (
+		tokens []string
+		b      strings.Builder
+		quoted bool
+	)
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			b.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if b.Len() > 0 {
+				tokens = append(tokens, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() > 0 {
+		tokens = append(tokens, b.String())
+	}
+	return tokens
+}
+
+func isFieldName(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// foldRunes returns the runes of s, each lowercased.
+func foldRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+func indexRunes(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if hasPrefixRunes(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasPrefixRunes(s, prefix []rune) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i, r := range prefix {
+		if s[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// byteToRuneIndexes converts byte offsets in s to rune indices.
+func byteToRuneIndexes(s string, offsets []int) []int {
+	indexes := make([]int, 0, len(offsets))
+	j := 0
+	for runeIndex, byteIndex := 0, 0; byteIndex < len(s) && j < len(offsets); runeIndex++ {
+		if byteIndex == offsets[j] {
+			indexes = append(indexes, runeIndex)
+			j++
+		}
+		_, size := utf8.DecodeRuneInString(s[byteIndex:])
+		byteIndex += size
+	}
+	return indexes
+}
+
+// uniqueSorted sorts the given ints and removes duplicates.
+func uniqueSorted(v []int) []int {
+	if len(v) == 0 {
+		return v
+	}
+	sort.Ints(v)
+	out := v[:1]
+	for _, i := range v[1:] {
+		if i != out[len(out)-1] {
+			out = append(out, i)
+		}
+	}
+	return out
+}
@@ -0,0 +1,168 @@
+package list
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ItemRemovedMsg is sent when the removal of an item confirmed by the user
+// is committed, once it can no longer be undone.
+type ItemRemovedMsg struct {
+	// Index is the index the item had in the unfiltered list of items when
+	// it was removed.
+	Index int
+
+	Item Item
+}
+
+// undoTimeoutMsg is sent when the time to undo a removal is up.
+type undoTimeoutMsg struct {
+	id  int
+	gen int
+}
+
+// removal is an item that's about to be removed, or that was removed and
+// can still be put back.
+type removal struct {
+	index int // index in the unfiltered list of items
+	item  Item
+}
+
+// SetRemovable enables or disables removing the selected item with the
+// RemoveItem keybinding. The user is asked to confirm each removal in the
+// status bar, and can undo it for StatusMessageLifetime afterwards, while
+// the title bar says so. See ConfirmRemoveItem.
+func (m *Model) SetRemovable(v bool) {
+	m.removable = v
+	m.updateKeybindings()
+}
+
+// Removable returns whether the user can remove items.
+func (m Model) Removable() bool {
+	return m.removable
+}
+
+// ConfirmRemoveItem asks the user to confirm removing the item at the given
+// index in the unfiltered list of items. Once confirmed, the item is removed
+// and the title bar offers to undo the removal for StatusMessageLifetime,
+// after which an ItemRemovedMsg is sent.
+func (m *Model) ConfirmRemoveItem(index int) {
+	if index < 0 || index >= len(m.items) {
+		return
+	}
+	m.pendingRemoval = &removal{index: index, item: m.items[index]}
+	m.updateKeybindings()
+}
+
+// RemovalPending returns whether the user is being asked to confirm a
+// removal.
+func (m Model) RemovalPending() bool {
+	return m.pendingRemoval != nil
+}
+
+// UndoRemove puts back the last item removed by the user, if the removal
+// can still be undone, and selects it. While filtered, the item is selected
+// once it's been filtered, if it matches. This returns a command.
+func (m *Model) UndoRemove() tea.Cmd {
+	if m.undoRemoval == nil {
+		return nil
+	}
+	r := *m.undoRemoval
+	m.undoRemoval = nil
+
+	cmd := m.InsertItem(r.index, r.item)
+	if cmd != nil {
+		m.selectAfterFilter = r.index
+	} else {
+		m.selectGlobal(r.index)
+	}
+	m.updateKeybindings()
+	return cmd
+}
+
+// handleRemovalPrompt handles keys while the user is asked to confirm a
+// removal. Other keys are ignored until they answer.
+func (m *Model) handleRemovalPrompt(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, m.KeyMap.ConfirmRemove):
+		return m.confirmRemoval()
+
+	case key.Matches(keyMsg, m.KeyMap.CancelRemove):
+		m.pendingRemoval = nil
+		m.updateKeybindings()
+	}
+	return nil
+}
+
+// confirmRemoval removes the item waiting for confirmation and offers to
+// undo it. An earlier removal that could still be undone is committed.
+func (m *Model) confirmRemoval() tea.Cmd {
+	r := *m.pendingRemoval
+	m.pendingRemoval = nil
+	committed := m.commitRemoval()
+
+	m.RemoveItem(r.index)
+	// Removing the last item leaves the cursor past the end.
+	m.Select(max(0, min(m.Index(), m.visibleCount()-1)))
+	m.undoRemoval = &r
+	m.removalGen++
+	m.updateKeybindings()
+
+	id, gen := m.id, m.removalGen
+	return tea.Batch(
+		committed,
+		tea.Tick(m.StatusMessageLifetime, func(time.Time) tea.Msg {
+			return undoTimeoutMsg{id: id, gen: gen}
+		}),
+	)
+}
+
+// commitRemoval makes the last removal final, returning a command that
+// reports it.
+func (m *Model) commitRemoval() tea.Cmd {
+	if m.undoRemoval == nil {
+		return nil
+	}
+	r := *m.undoRemoval
+	m.undoRemoval = nil
+	m.updateKeybindings()
+	return func() tea.Msg {
+		return ItemRemovedMsg{Index: r.index, Item: r.item}
+	}
+}
+
+// undoHintView renders the hint shown in the title bar while the last
+// removal can be undone.
+func (m Model) undoHintView() string {
+	hint := fmt.Sprintf("Removed “%s”", itemName(m.undoRemoval.item))
+	if b := m.KeyMap.UndoRemove.Help(); b.Key != "" {
+		hint += "  " + m.Styles.StatusBarFilterCount.Render(b.Key+" "+b.Desc)
+	}
+	return hint
+}
+
+// removalPromptView renders the confirmation prompt shown in the status bar.
+func (m Model) removalPromptView() string {
+	prompt := fmt.Sprintf("Remove “%s”?", itemName(m.pendingRemoval.item))
+	yes, no := m.KeyMap.ConfirmRemove.Help().Key, m.KeyMap.CancelRemove.Help().Key
+	return m.Styles.StatusBarConfirm.Render(prompt) + " " +
+		m.Styles.StatusBarFilterCount.Render(yes+"/"+no)
+}
+
+// itemName returns a short name for an item to show in prompts.
+func itemName(item Item) string {
+	name := item.FilterValue()
+	if i, ok := item.(DefaultItem); ok {
+		name = i.Title()
+	}
+	return ansi.Truncate(name, 20, ellipsis) //nolint:gomnd
+}
@@ -0,0 +1,124 @@
+package list
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// ItemsReorderedMsg is sent when the user moves an item with the reordering
+// keybindings. From and To are indexes in the unfiltered list of items, as
+// with GlobalIndex.
+type ItemsReorderedMsg struct {
+	From, To int
+	Item     Item
+}
+
+// SetReorderable enables or disables moving items with the keyboard. Items
+// can only be moved while no filter is applied and no sort order is active,
+// so that they're shown in the order they're stored, and not in tree mode.
+// When items are grouped they're moved within their group.
+func (m *Model) SetReorderable(v bool) {
+	m.reorderable = v
+	m.updateKeybindings()
+}
+
+// Reorderable returns whether items can be moved with the keyboard.
+func (m Model) Reorderable() bool {
+	return m.reorderable
+}
+
+// MoveItem moves the item at index from to index to, shifting the items in
+// between. Both are indexes in the unfiltered list of items. The selected
+// and marked items stay selected and marked. This returns a command.
+func (m *Model) MoveItem(from, to int) tea.Cmd {
+	if from == to || from < 0 || to < 0 || from >= len(m.items) || to >= len(m.items) {
+		return nil
+	}
+
+	var selected int
+	hasSelection := m.visibleCount() > 0
+	if hasSelection {
+		selected = m.GlobalIndex()
+	}
+
+	item := m.items[from]
+	m.items = removeItemFromSlice(m.items, from)
+	m.items = insertItemIntoSlice(m.items, item, to)
+	if m.tree != nil {
+		node := m.tree[from]
+		m.removeTreeNode(from)
+		m.insertTreeNode(to)
+		m.tree[to] = node
+	}
+
+	if len(m.marked) > 0 {
+		marked := make(map[int]struct{}, len(m.marked))
+		for i := range m.marked {
+			marked[movedIndex(i, from, to)] = struct{}{}
+		}
+		m.marked = marked
+	}
+	m.resort()
+
+	var cmd tea.Cmd
+	if m.filterState != Unfiltered {
+		cmd = m.filterCmd()
+	}
+
+	m.updatePagination()
+	if hasSelection {
+		m.selectGlobal(movedIndex(selected, from, to))
+	}
+	return cmd
+}
+
+// canReorder returns whether the selected item can be moved, which is when
+// items are shown in the order they're stored.
+func (m Model) canReorder() bool {
+	return m.reorderable &&
+		len(m.items) > 0 &&
+		m.tree == nil &&
+		m.filterState == Unfiltered &&
+		m.sortLess() == nil
+}
+
+// moveSelected moves the selected item to the position of the visible item
+// at the given index, staying within the item's group, and returns a
+// command that reports the move.
+func (m *Model) moveSelected(target int) tea.Cmd {
+	if !m.canReorder() || m.SelectedItem() == nil {
+		return nil
+	}
+
+	index := m.Index()
+	first, last := 0, m.visibleCount()-1
+	if m.sections != nil && index < len(m.sectionOf) {
+		s := m.sections[m.sectionOf[index]]
+		first, last = s.start, s.end-1
+	}
+	target = max(first, min(last, target))
+	if target == index {
+		return nil
+	}
+
+	from, to := m.GlobalIndex(), m.globalIndexOf(target)
+	if from < 0 || from >= len(m.items) {
+		return nil
+	}
+	item := m.items[from]
+	cmd := m.MoveItem(from, to)
+	return tea.Batch(cmd, func() tea.Msg {
+		return ItemsReorderedMsg{From: from, To: to, Item: item}
+	})
+}
+
+// movedIndex returns the new index of the item at index i after moving the
+// item at from to to.
+func movedIndex(i, from, to int) int {
+	switch {
+	case i == from:
+		return to
+	case from < to && i > from && i <= to:
+		return i - 1
+	case to < from && i >= to && i < from:
+		return i + 1
+	}
+	return i
+}
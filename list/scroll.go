@@ -0,0 +1,193 @@
+package list
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SetScrollMode enables or disables scroll mode. In scroll mode the list
+// isn't split into pages: items slide into view one at a time as the cursor
+// nears the top or bottom, keeping ScrollOff items around the cursor where
+// possible. The pagination dots are replaced by an optional scrollbar; see
+// SetShowScrollbar. Enabling scroll mode turns off grid layout.
+func (m *Model) SetScrollMode(v bool) {
+	if v == m.scrollMode {
+		return
+	}
+	if v && m.gridCellWidth > 0 {
+		m.SetGridLayout(0)
+	}
+
+	index := m.Index()
+	m.scrollMode = v
+	m.scrollOffset = 0
+	m.Paginator.Page = 0
+	m.cursor = 0
+	if v {
+		m.Paginator.TotalPages = 1
+	}
+
+	m.updatePagination()
+	m.Select(index)
+	m.updateKeybindings()
+}
+
+// ScrollMode returns whether scroll mode is enabled.
+func (m Model) ScrollMode() bool {
+	return m.scrollMode
+}
+
+// SetShowScrollbar shows or hides the scrollbar in scroll mode. The
+// scrollbar is only shown when there are more items than fit in view.
+func (m *Model) SetShowScrollbar(v bool) {
+	m.showScrollbar = v
+}
+
+// ShowScrollbar returns whether the scrollbar is set to be shown in scroll
+// mode.
+func (m Model) ShowScrollbar() bool {
+	return m.showScrollbar
+}
+
+// updateScroll scrolls the list so that the cursor is in view, with
+// ScrollOff items above and below it where possible.
+func (m *Model) updateScroll() {
+	if !m.scrollMode {
+		return
+	}
+
+	items := m.VisibleItems()
+	if len(items) == 0 {
+		m.scrollOffset = 0
+		return
+	}
+	index := max(0, min(m.Index(), len(items)-1))
+
+	// The margin can't take up more than half of the items in view, or
+	// the cursor couldn't move without scrolling in both directions.
+	window := m.scrollEnd(items, min(m.scrollOffset, index)) - min(m.scrollOffset, index)
+	margin := min(max(0, m.ScrollOff), (window-1)/2) //nolint:gomnd
+
+	if index-margin < m.scrollOffset {
+		m.scrollOffset = max(0, index-margin)
+	}
+	if start := m.scrollStart(items, min(index+margin, len(items)-1)); start > m.scrollOffset {
+		m.scrollOffset = max(0, min(start, index))
+	}
+
+	// The header of a group repeats at the top of the view, which
+	// scrollStart doesn't count, so the cursor may still be a little out of
+	// view.
+	for m.scrollOffset < index {
+		end := m.scrollEnd(items, m.scrollOffset)
+		if index+margin < end || end == len(items) {
+			break
+		}
+		m.scrollOffset++
+	}
+
+	// Don't leave empty space below the last item.
+	for m.scrollOffset > 0 && m.scrollEnd(items, m.scrollOffset-1) == len(items) {
+		m.scrollOffset--
+	}
+}
+
+// scrollEnd returns the index after the last item that fits in view when
+// scrolled to the item at offset.
+func (m Model) scrollEnd(items []Item, offset int) int {
+	var (
+		spacing = m.delegate.Spacing()
+		used    int
+	)
+	for i := offset; i < len(items); i++ {
+		used += m.rowsFor(items, i, offset)
+		if i > offset && used-spacing > m.itemsHeight {
+			return i
+		}
+	}
+	return len(items)
+}
+
+// scrollStart returns the index of the first item in view when scrolled so
+// that the item at last is at the bottom of the view. It's the counterpart
+// of scrollEnd, except that it doesn't count the header repeated at the top
+// of the view for a group continuing from above.
+func (m Model) scrollStart(items []Item, last int) int {
+	var (
+		spacing = m.delegate.Spacing()
+		used    int
+	)
+	for i := last; i >= 0; i-- {
+		used += m.rowsFor(items, i, -1)
+		if i < last && used-spacing > m.itemsHeight {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// scrollPage scrolls by the number of items in view, in the given
+// direction, moving the cursor along.
+func (m *Model) scrollPage(dir int) {
+	items := m.VisibleItems()
+	if len(items) == 0 {
+		return
+	}
+
+	window := max(1, m.scrollEnd(items, m.scrollOffset)-m.scrollOffset)
+	index := m.Index()
+	m.scrollOffset = max(0, m.scrollOffset+dir*window)
+	m.Select(max(0, min(len(items)-1, index+dir*window)))
+}
+
+// scrollView renders the items in view in scroll mode, along with the
+// scrollbar.
+func (m Model) scrollView() string {
+	items := m.VisibleItems()
+	start := min(m.scrollOffset, len(items))
+	end := m.scrollEnd(items, start)
+
+	if !m.scrollbarShown() {
+		return m.itemsView(items, start, end)
+	}
+
+	// Leave room for the scrollbar.
+	bar := m.scrollbarView(len(items), start, end)
+	m.width -= lipgloss.Width(bar)
+
+	content := lipgloss.NewStyle().Width(m.width).Render(m.itemsView(items, start, end))
+	return lipgloss.JoinHorizontal(lipgloss.Top, content, bar)
+}
+
+// scrollbarShown returns whether the scrollbar is shown, which is when it's
+// enabled in scroll mode and not all items fit in view.
+func (m Model) scrollbarShown() bool {
+	if !m.scrollMode || !m.showScrollbar {
+		return false
+	}
+	start, end := m.itemsInView()
+	return start > 0 || end < m.visibleCount()
+}
+
+// scrollbarView renders a vertical scrollbar for the given range of items in
+// view.
+func (m Model) scrollbarView(total, start, end int) string {
+	height := max(1, m.itemsHeight)
+	thumb := max(1, height*(end-start)/total)
+
+	var pos int
+	if hidden := total - (end - start); hidden > 0 {
+		pos = min(height-thumb, (height-thumb)*start/hidden)
+	}
+
+	rows := make([]string, height)
+	for i := range rows {
+		if i >= pos && i < pos+thumb {
+			rows[i] = m.Styles.ScrollbarThumb.String()
+		} else {
+			rows[i] = m.Styles.ScrollbarTrack.String()
+		}
+	}
+	return strings.Join(rows, "\n")
+}
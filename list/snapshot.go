@@ -0,0 +1,97 @@
+package list
+
+// Snapshot is the navigation state of a list: the selection, the page, the
+// filter and the sort order. It can be encoded as JSON to persist it across
+// program runs. See Model.Snapshot and Model.Restore.
+type Snapshot struct {
+	// Index is the index of the selected item in the unfiltered list of
+	// items, as with GlobalIndex.
+	Index int `json:"index"`
+
+	// Page and Cursor are the page the selection is on and the cursor's
+	// position on it, as with Paginator.Page and Cursor. They're used when
+	// the item at Index isn't visible anymore. In scroll mode, where the
+	// cursor's position depends on how far the list is scrolled, Page is 0
+	// and Cursor is the position of the selection among the visible items.
+	Page   int `json:"page"`
+	Cursor int `json:"cursor"`
+
+	Filter string `json:"filter,omitempty"`
+
+	// FilterState is the name of the filter state, as returned by
+	// FilterState.String, so that it reads well when encoded.
+	FilterState string `json:"filterState"`
+
+	// SortOrder is the name of the active sort order, if any.
+	SortOrder string `json:"sortOrder,omitempty"`
+}
+
+// Snapshot captures the list's navigation state so that it can be restored
+// later with Restore, such as when returning to a screen.
+func (m Model) Snapshot() Snapshot {
+	s := Snapshot{
+		Index:       m.GlobalIndex(),
+		Page:        m.Paginator.Page,
+		Cursor:      m.Cursor(),
+		Filter:      m.FilterInput.Value(),
+		FilterState: m.filterState.String(),
+	}
+	if m.scrollMode {
+		s.Page, s.Cursor = 0, m.Index()
+	}
+	if o, ok := m.SortOrder(); ok {
+		s.SortOrder = o.Name
+	}
+	return s
+}
+
+// Restore brings the list back to the state captured by Snapshot. Sort
+// orders and filter states are matched by name and ignored if there's no
+// such order or state. The item that was selected is selected again if it's
+// still visible; otherwise the cursor goes back to the same position on the
+// same page, as far as the items allow.
+func (m *Model) Restore(s Snapshot) {
+	for i, o := range m.sortOrders {
+		if o.Name == s.SortOrder {
+			m.SetSortOrder(i)
+			break
+		}
+	}
+
+	m.resetFiltering()
+	state := filterStateNamed(s.FilterState)
+	if m.filteringEnabled && (state == Filtering || state == FilterApplied) {
+		m.SetFilterText(s.Filter)
+		if state == Filtering {
+			m.SetFilterState(Filtering)
+		}
+	}
+
+	items := m.VisibleItems()
+	if len(items) == 0 {
+		return
+	}
+	for i := range items {
+		if m.globalIndexOf(i) == s.Index {
+			m.Select(i)
+			return
+		}
+	}
+
+	var start int
+	if !m.scrollMode {
+		start, _ = m.pageBounds(max(0, min(s.Page, m.Paginator.TotalPages-1)))
+	}
+	m.Select(max(0, min(len(items)-1, start+s.Cursor)))
+}
+
+// filterStateNamed returns the filter state with the given name, as
+// returned by FilterState.String, or Unfiltered if there's no such state.
+func filterStateNamed(name string) FilterState {
+	for f := Unfiltered; f <= FilterApplied; f++ {
+		if f.String() == name {
+			return f
+		}
+	}
+	return Unfiltered
+}
@@ -0,0 +1,162 @@
+package list
+
+import (
+	"sort"
+	"time"
+)
+
+// SortFunc reports whether item a should be listed before item b.
+type SortFunc func(a, b Item) bool
+
+// SortOrder is a named order in which the list can present its items.
+type SortOrder struct {
+	// Name is shown in the title bar while the order is active.
+	Name string
+
+	// Less defines the order. If it's nil items are listed in the order
+	// they were added.
+	Less SortFunc
+}
+
+// SetSortOrders sets the orders the user can cycle through with the
+// CycleSortOrder binding, and activates the first one. Pass no orders to
+// list items in the order they were added.
+func (m *Model) SetSortOrders(orders ...SortOrder) {
+	m.sortOrders = orders
+	m.sortIndex = 0
+	m.resort()
+	m.updateKeybindings()
+}
+
+// SortOrders returns the orders the list can be sorted by.
+func (m Model) SortOrders() []SortOrder {
+	return m.sortOrders
+}
+
+// SortOrder returns the active sort order, and false if there is none.
+func (m Model) SortOrder() (SortOrder, bool) {
+	if m.sortIndex >= len(m.sortOrders) {
+		return SortOrder{}, false
+	}
+	return m.sortOrders[m.sortIndex], true
+}
+
+// SetSortOrder activates the sort order at the given index in SortOrders.
+// The selected item stays selected.
+func (m *Model) SetSortOrder(index int) {
+	if index < 0 || index >= len(m.sortOrders) {
+		return
+	}
+
+	var selected int
+	hasSelection := m.visibleCount() > 0
+	if hasSelection {
+		selected = m.GlobalIndex()
+	}
+
+	m.sortIndex = index
+	m.resort()
+	if m.filterState != Unfiltered {
+		m.sortFilteredItems()
+	}
+	m.updatePagination()
+
+	if hasSelection {
+		m.selectGlobal(selected)
+	}
+	m.updateKeybindings()
+}
+
+// CycleSortOrder activates the next sort order, wrapping around after the
+// last one.
+func (m *Model) CycleSortOrder() {
+	if len(m.sortOrders) == 0 {
+		return
+	}
+	m.SetSortOrder((m.sortIndex + 1) % len(m.sortOrders))
+}
+
+// sortLess returns the active SortFunc, if any.
+func (m Model) sortLess() SortFunc {
+	if o, ok := m.SortOrder(); ok {
+		return o.Less
+	}
+	return nil
+}
+
+// resort rebuilds the sorted view of the unfiltered items.
+func (m *Model) resort() {
+	less := m.sortLess()
+	if less == nil {
+		m.sortedItems = nil
+		return
+	}
+
+	sorted := m.itemsAsFilterItems()
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i].item, sorted[j].item)
+	})
+	m.sortedItems = sorted
+}
+
+// sortFilteredItems puts the filtered items in the active sort order if
+// SortFilterResults is set. Otherwise they're ranked by their scores across
+// all chunks filtered, blended with their frecency scores if a Frecency is
+// set. With an empty filter, items are always sorted.
+func (m *Model) sortFilteredItems() {
+	less := m.sortLess()
+	if less != nil && (m.SortFilterResults || m.FilterInput.Value() == "") {
+		sort.SliceStable(m.filteredItems, func(i, j int) bool {
+			return less(m.filteredItems[i].item, m.filteredItems[j].item)
+		})
+		return
+	}
+
+	// Frecency scores are taken at the same time for all items, so that
+	// they compare consistently.
+	frecency, now := m.frecency, time.Now()
+	if m.FilterInput.Value() == "" {
+		frecency = nil
+	}
+
+	ranked := rankedItems{items: m.filteredItems, scores: make([]float64, len(m.filteredItems))}
+	for i, fi := range m.filteredItems {
+		ranked.scores[i] = fi.score
+		if frecency != nil {
+			ranked.scores[i] += frecency.Weight * frecency.scoreAt(fi.item, now)
+		}
+	}
+	sort.Stable(ranked)
+}
+
+// rankedItems sorts filtered items by score, highest first, and then by
+// their rank.
+type rankedItems struct {
+	items  filteredItems
+	scores []float64
+}
+
+func (r rankedItems) Len() int { return len(r.items) }
+
+func (r rankedItems) Less(i, j int) bool {
+	if r.scores[i] != r.scores[j] {
+		return r.scores[i] > r.scores[j]
+	}
+	return r.items[i].rank < r.items[j].rank
+}
+
+func (r rankedItems) Swap(i, j int) {
+	r.items[i], r.items[j] = r.items[j], r.items[i]
+	r.scores[i], r.scores[j] = r.scores[j], r.scores[i]
+}
+
+// selectGlobal selects the visible item with the given index in the
+// unfiltered list of items, if it's visible.
+func (m *Model) selectGlobal(index int) {
+	for i := range m.VisibleItems() {
+		if m.globalIndexOf(i) == index {
+			m.Select(i)
+			return
+		}
+	}
+}
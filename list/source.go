@@ -0,0 +1,166 @@
+package list
+
+import (
+	"context"
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ItemSource provides items to the list incrementally, for lists that are too
+// large to load at once or that come from a remote service. The list fetches
+// more items as the user approaches the end of the items loaded so far.
+type ItemSource interface {
+	// Fetch returns up to limit items starting at offset, along with the
+	// total number of items available, or -1 if it isn't known. Returning
+	// fewer than limit items signals the end of the source.
+	//
+	// Fetch is called from a command, outside of the Bubble Tea event loop.
+	// It should stop and return ctx.Err() when ctx is canceled, which happens
+	// when the source is replaced.
+	Fetch(ctx context.Context, offset, limit int) (items []Item, total int, err error)
+}
+
+// itemsFetchedMsg contains items fetched from an ItemSource.
+type itemsFetchedMsg struct {
+	id    int // ID of the list
+	gen   int // generation of the source the items came from
+	items []Item
+	total int
+	limit int
+	err   error
+}
+
+// SetItemSource replaces the items in the list with ones fetched from the
+// given source. Any fetch still running for a previous source is canceled.
+// Pass nil to detach the current source, leaving the loaded items in place.
+// This returns a command.
+func (m *Model) SetItemSource(src ItemSource) tea.Cmd {
+	if m.sourceCancel != nil {
+		m.sourceCancel()
+		m.sourceCancel = nil
+	}
+	m.sourceGen++
+	m.stopFetching()
+
+	m.source = src
+	if src == nil {
+		return nil
+	}
+
+	m.sourceTotal = -1
+	m.sourceDone = false
+	m.sourceErr = nil
+	cmd := m.SetItems(nil)
+	return tea.Batch(cmd, m.fetchMore())
+}
+
+// Loading returns whether items are currently being fetched from the
+// ItemSource.
+func (m Model) Loading() bool {
+	return m.fetching
+}
+
+// SourceErr returns the error returned by the last fetch from the
+// ItemSource, if any.
+func (m Model) SourceErr() error {
+	return m.sourceErr
+}
+
+// moreToFetch returns whether the ItemSource has items that haven't been
+// fetched yet.
+func (m Model) moreToFetch() bool {
+	return m.source != nil && !m.sourceDone
+}
+
+// maybeFetchMore fetches more items if the selected item is on the last page
+// of the items loaded so far.
+func (m *Model) maybeFetchMore() tea.Cmd {
+	if !m.moreToFetch() || m.fetching {
+		return nil
+	}
+	if m.Index() < m.visibleCount()-m.Paginator.PerPage {
+		return nil
+	}
+	return m.fetchMore()
+}
+
+// fetchMore fetches the next batch of items from the ItemSource.
+func (m *Model) fetchMore() tea.Cmd {
+	if !m.moreToFetch() || m.fetching {
+		return nil
+	}
+
+	limit := m.FetchSize
+	if limit <= 0 {
+		limit = 2 * max(1, m.Paginator.PerPage) //nolint:gomnd
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.sourceCancel = cancel
+	m.fetching = true
+
+	// Indicate activity with the spinner, unless it's already in use.
+	var spinnerCmd tea.Cmd
+	if !m.showSpinner {
+		m.fetchSpinner = true
+		spinnerCmd = m.StartSpinner()
+	}
+
+	src, id, gen, offset := m.source, m.id, m.sourceGen, len(m.items)
+	return tea.Batch(spinnerCmd, func() tea.Msg {
+		defer cancel()
+		items, total, err := src.Fetch(ctx, offset, limit)
+		return itemsFetchedMsg{
+			id:    id,
+			gen:   gen,
+			items: items,
+			total: total,
+			limit: limit,
+			err:   err,
+		}
+	})
+}
+
+// handleFetched adds fetched items to the list.
+func (m *Model) handleFetched(msg itemsFetchedMsg) tea.Cmd {
+	m.stopFetching()
+	m.sourceCancel = nil
+
+	if msg.err != nil {
+		if errors.Is(msg.err, context.Canceled) {
+			return nil
+		}
+		m.sourceErr = msg.err
+		return m.NewStatusMessage(msg.err.Error())
+	}
+
+	var cmd tea.Cmd
+	m.items = append(m.items, msg.items...)
+	m.resort()
+	if m.filterState != Unfiltered {
+		cmd = m.filterCmd()
+	}
+
+	if msg.total >= 0 {
+		m.sourceTotal = msg.total
+	}
+	m.sourceDone = len(msg.items) < msg.limit ||
+		(m.sourceTotal >= 0 && len(m.items) >= m.sourceTotal)
+
+	m.updatePagination()
+	m.updateKeybindings()
+
+	// Keep going if we still haven't loaded past the selected page.
+	return tea.Batch(cmd, m.maybeFetchMore())
+}
+
+// stopFetching clears the fetching state and stops the spinner if we
+// started it.
+func (m *Model) stopFetching() {
+	m.fetching = false
+	if m.fetchSpinner {
+		m.fetchSpinner = false
+		m.StopSpinner()
+	}
+}
@@ -0,0 +1,159 @@
+package list
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// StatusSegment renders a part of the status bar, such as the number of
+// items. It returns an empty string when there's nothing to show, in which
+// case the segment is left out. See SetStatusBarSegments.
+type StatusSegment func(m Model) string
+
+// DefaultStatusSegments returns the segments shown on the left of the status
+// bar by default: the summary of the items, the number of items filtered out
+// and whether more items are loading.
+func DefaultStatusSegments() []StatusSegment {
+	return []StatusSegment{StatusSummary, StatusFilteredCount, StatusLoadingMore}
+}
+
+// SetStatusBarSegments sets the segments shown on the left and right of the
+// status bar. Segments on each side are separated by the DividerDot style,
+// and the right side is aligned with the right edge of the list. When
+// there's not enough room the left side is truncated first.
+func (m *Model) SetStatusBarSegments(left, right []StatusSegment) {
+	m.statusLeft = left
+	m.statusRight = right
+	m.updatePagination()
+}
+
+// StatusBarSegments returns the segments shown on the left and right of the
+// status bar.
+func (m Model) StatusBarSegments() (left, right []StatusSegment) {
+	return m.statusLeft, m.statusRight
+}
+
+// StatusSummary is a StatusSegment showing the number of visible items,
+// preceded by the filter when one is applied, or the state of the list when
+// there are none. See StatusFilter and StatusItemCount.
+func StatusSummary(m Model) string {
+	count := StatusItemCount(m)
+	if f := StatusFilter(m); f != "" && len(m.items) > 0 {
+		return f + " " + count
+	}
+	return count
+}
+
+// StatusItemCount is a StatusSegment showing the number of visible items,
+// along with the total number of items to fetch from an ItemSource when
+// it's known. When there are no items to show, it shows why.
+func StatusItemCount(m Model) string {
+	visibleItems := m.visibleCount()
+
+	itemName := m.itemNameSingular
+	if visibleItems != 1 {
+		itemName = m.itemNamePlural
+	}
+
+	itemsDisplay := fmt.Sprintf("%d %s", visibleItems, itemName)
+	if m.moreToFetch() && m.filterState == Unfiltered {
+		if m.sourceTotal >= 0 {
+			itemsDisplay = fmt.Sprintf("%d of %d %s", visibleItems, m.sourceTotal, m.itemNamePlural)
+		} else {
+			itemsDisplay = fmt.Sprintf("%d+ %s", visibleItems, m.itemNamePlural)
+		}
+	}
+
+	switch {
+	case m.filterState == Filtering && visibleItems == 0:
+		return m.Styles.StatusEmpty.Render("Nothing matched")
+	case m.filterState == Filtering:
+		return itemsDisplay
+	case len(m.items) == 0 && m.fetching:
+		// Waiting for the first items from the source.
+		return m.Styles.StatusEmpty.Render("Loading " + m.itemNamePlural + "…")
+	case len(m.items) == 0:
+		return m.Styles.StatusEmpty.Render("No " + m.itemNamePlural)
+	}
+	return itemsDisplay
+}
+
+// StatusFilter is a StatusSegment showing the applied filter, quoted and
+// shortened.
+func StatusFilter(m Model) string {
+	if m.filterState != FilterApplied {
+		return ""
+	}
+	f := strings.TrimSpace(m.FilterInput.Value())
+	return fmt.Sprintf("“%s”", ansi.Truncate(f, 10, ellipsis)) //nolint:gomnd
+}
+
+// StatusFilteredCount is a StatusSegment showing the number of items hidden
+// by the filter. Items in collapsed groups and nodes aren't counted.
+func StatusFilteredCount(m Model) string {
+	if m.filterState == Unfiltered {
+		return ""
+	}
+	numFiltered := len(m.items) - len(m.filteredItems)
+	if numFiltered <= 0 {
+		return ""
+	}
+	return m.Styles.StatusBarFilterCount.Render(fmt.Sprintf("%d filtered", numFiltered))
+}
+
+// StatusLoadingMore is a StatusSegment showing that more items are being
+// fetched from the ItemSource.
+func StatusLoadingMore(m Model) string {
+	if !m.fetching || len(m.items) == 0 {
+		return ""
+	}
+	return m.Styles.StatusBarFilterCount.Render("loading more…")
+}
+
+// StatusSortOrder is a StatusSegment showing the name of the active sort
+// order.
+func StatusSortOrder(m Model) string {
+	o, ok := m.SortOrder()
+	if !ok || o.Name == "" {
+		return ""
+	}
+	return m.Styles.SortIndicator.Render(o.Name)
+}
+
+// StatusMarkedCount is a StatusSegment showing the number of marked items
+// in multi-select mode.
+func StatusMarkedCount(m Model) string {
+	if !m.multiSelect || len(m.marked) == 0 {
+		return ""
+	}
+	return m.Styles.StatusBarMarkedCount.Render(fmt.Sprintf("%d marked", len(m.marked)))
+}
+
+// statusSegmentsView renders the given segments, leaving out empty ones.
+func (m Model) statusSegmentsView(segments []StatusSegment) string {
+	var parts []string
+	for _, s := range segments {
+		if v := s(m); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, m.Styles.DividerDot.String())
+}
+
+// layoutStatus places left and right at either end of the given width,
+// truncating left, and then right, if they don't fit.
+func layoutStatus(left, right string, width int) string {
+	if right == "" {
+		return ansi.Truncate(left, width, ellipsis)
+	}
+
+	rightWidth := ansi.StringWidth(right)
+	if rightWidth >= width {
+		return ansi.Truncate(right, width, ellipsis)
+	}
+	left = ansi.Truncate(left, width-rightWidth-1, ellipsis)
+	gap := width - ansi.StringWidth(left) - rightWidth
+	return left + strings.Repeat(" ", gap) + right
+}
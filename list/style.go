@@ -12,11 +12,12 @@ const (
 // Styles contains style definitions for this list component. By default, these
 // values are generated by DefaultStyles.
 type Styles struct {
-	TitleBar     lipgloss.Style
-	Title        lipgloss.Style
-	Spinner      lipgloss.Style
-	FilterPrompt lipgloss.Style
-	FilterCursor lipgloss.Style
+	TitleBar      lipgloss.Style
+	Title         lipgloss.Style
+	SortIndicator lipgloss.Style
+	Spinner       lipgloss.Style
+	FilterPrompt  lipgloss.Style
+	FilterCursor  lipgloss.Style
 
 	// Default styling for matched characters in a filter. This can be
 	// overridden by delegates.
@@ -26,12 +27,39 @@ type Styles struct {
 	StatusEmpty           lipgloss.Style
 	StatusBarActiveFilter lipgloss.Style
 	StatusBarFilterCount  lipgloss.Style
+	StatusBarMarkedCount  lipgloss.Style
+	StatusBarConfirm      lipgloss.Style
 
 	NoItems lipgloss.Style
 
+	// Section headers shown above each group of items, when items are
+	// grouped. Items aren't grouped in tree mode or grid layout.
+	GroupHeader        lipgloss.Style
+	GroupCount         lipgloss.Style
+	GroupExpandedIcon  lipgloss.Style
+	GroupCollapsedIcon lipgloss.Style
+
+	// Guide lines and expand indicators in tree mode.
+	TreeGuide         lipgloss.Style
+	TreeExpandedIcon  lipgloss.Style
+	TreeCollapsedIcon lipgloss.Style
+
 	PaginationStyle lipgloss.Style
 	HelpStyle       lipgloss.Style
 
+	// The preview pane, when a PreviewFunc is set.
+	Preview lipgloss.Style
+
+	// The menu of actions for the selected item.
+	ActionMenu             lipgloss.Style
+	ActionMenuItem         lipgloss.Style
+	ActionMenuSelectedItem lipgloss.Style
+	ActionMenuKey          lipgloss.Style
+
+	// The scrollbar shown in scroll mode.
+	ScrollbarTrack lipgloss.Style
+	ScrollbarThumb lipgloss.Style
+
 	// Styled characters.
 	ActivePaginationDot   lipgloss.Style
 	InactivePaginationDot lipgloss.Style
@@ -52,6 +80,10 @@ func DefaultStyles() (s Styles) {
 		Foreground(lipgloss.Color("230")).
 		Padding(0, 1)
 
+	s.SortIndicator = lipgloss.NewStyle().
+		Foreground(subduedColor).
+		SetString("⇅")
+
 	s.Spinner = lipgloss.NewStyle().
 		Foreground(lipgloss.AdaptiveColor{Light: "#8E8E8E", Dark: "#747373"})
 
@@ -74,13 +106,71 @@ func DefaultStyles() (s Styles) {
 
 	s.StatusBarFilterCount = lipgloss.NewStyle().Foreground(verySubduedColor)
 
+	s.StatusBarMarkedCount = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#EE6FF8", Dark: "#EE6FF8"})
+
+	s.StatusBarConfirm = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#FF4672", Dark: "#ED567A"})
+
 	s.NoItems = lipgloss.NewStyle().
 		Foreground(lipgloss.AdaptiveColor{Light: "#909090", Dark: "#626262"})
 
+	s.GroupHeader = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#dddddd"}).
+		Bold(true).
+		PaddingLeft(2) //nolint:gomnd
+
+	s.GroupCount = lipgloss.NewStyle().Foreground(subduedColor)
+
+	s.GroupExpandedIcon = lipgloss.NewStyle().
+		Foreground(subduedColor).
+		SetString("▾")
+
+	s.GroupCollapsedIcon = lipgloss.NewStyle().
+		Foreground(subduedColor).
+		SetString("▸")
+
+	s.Preview = lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(verySubduedColor).
+		PaddingLeft(1)
+
+	s.ActionMenu = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(subduedColor).
+		Padding(0, 1).
+		MarginLeft(4) //nolint:gomnd
+
+	s.ActionMenuItem = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#dddddd"})
+
+	s.ActionMenuSelectedItem = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#EE6FF8", Dark: "#EE6FF8"})
+
+	s.ActionMenuKey = lipgloss.NewStyle().Foreground(subduedColor)
+
+	s.TreeGuide = lipgloss.NewStyle().Foreground(verySubduedColor)
+
+	s.TreeExpandedIcon = lipgloss.NewStyle().
+		Foreground(subduedColor).
+		SetString("▾")
+
+	s.TreeCollapsedIcon = lipgloss.NewStyle().
+		Foreground(subduedColor).
+		SetString("▸")
+
 	s.ArabicPagination = lipgloss.NewStyle().Foreground(subduedColor)
 
 	s.PaginationStyle = lipgloss.NewStyle().PaddingLeft(2) //nolint:gomnd
 
+	s.ScrollbarTrack = lipgloss.NewStyle().
+		Foreground(verySubduedColor).
+		SetString("│")
+
+	s.ScrollbarThumb = lipgloss.NewStyle().
+		Foreground(subduedColor).
+		SetString("┃")
+
 	s.HelpStyle = lipgloss.NewStyle().Padding(1, 0, 0, 2)
 
 	s.ActivePaginationDot = lipgloss.NewStyle().
@@ -0,0 +1,321 @@
+package list
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TreeItem is an item with children, for browsing nested data in tree mode.
+// See SetTree.
+type TreeItem interface {
+	Item
+
+	// Children returns the item's children. They may implement TreeItem
+	// themselves.
+	Children() []Item
+}
+
+// treeNode holds the tree state of an item in tree mode.
+type treeNode struct {
+	depth    int
+	expanded bool
+}
+
+// treeRow describes a visible item in tree mode.
+type treeRow struct {
+	depth       int
+	parent      int  // index of the parent in the visible items, or -1
+	last        bool // whether it's the last of its siblings in view
+	hasChildren bool
+	open        bool // whether its children are in view
+}
+
+// SetTree puts the list in tree mode and replaces its items with the given
+// roots and all of their descendants, in depth-first order. Items are stored
+// flattened, so GlobalIndex and SetItem refer to nodes anywhere in the tree.
+//
+// Only the roots are visible at first. Nodes are expanded and collapsed with
+// the ExpandNode and CollapseNode keybindings, or with SetNodeExpanded. While
+// a filter is applied the ancestors of matching nodes are shown too. Sort
+// orders and groups don't apply in tree mode. Calling SetItems leaves tree
+// mode. This returns a command.
+func (m *Model) SetTree(roots []Item) tea.Cmd {
+	var (
+		items []Item
+		nodes []treeNode
+		walk  func([]Item, int)
+	)
+	walk = func(children []Item, depth int) {
+		for _, item := range children {
+			items = append(items, item)
+			nodes = append(nodes, treeNode{depth: depth})
+			if t, ok := item.(TreeItem); ok {
+				walk(t.Children(), depth+1)
+			}
+		}
+	}
+	walk(roots, 0)
+
+	cmd := m.SetItems(items)
+	m.tree = nodes
+	if m.tree == nil {
+		m.tree = []treeNode{}
+	}
+	m.updatePagination()
+	m.updateKeybindings()
+	return cmd
+}
+
+// TreeMode returns whether the list is in tree mode.
+func (m Model) TreeMode() bool {
+	return m.tree != nil
+}
+
+// SetNodeExpanded expands or collapses the node at the given index in the
+// unfiltered list of items. The selected item stays selected if it's still
+// visible.
+func (m *Model) SetNodeExpanded(index int, expanded bool) {
+	if index < 0 || index >= len(m.tree) || m.tree[index].expanded == expanded {
+		return
+	}
+
+	var selected int
+	hasSelection := m.visibleCount() > 0
+	if hasSelection {
+		selected = m.GlobalIndex()
+	}
+
+	m.tree[index].expanded = expanded
+	m.updatePagination()
+	if hasSelection {
+		m.selectGlobal(selected)
+	}
+}
+
+// NodeExpanded returns whether the node at the given index in the unfiltered
+// list of items is expanded.
+func (m Model) NodeExpanded(index int) bool {
+	return index >= 0 && index < len(m.tree) && m.tree[index].expanded
+}
+
+// ExpandNode expands the selected node. If it's already expanded the cursor
+// moves to its first child.
+func (m *Model) ExpandNode() {
+	row, ok := m.selectedRow()
+	if !ok || !row.hasChildren {
+		return
+	}
+	if row.open {
+		m.CursorDown()
+		return
+	}
+	m.SetNodeExpanded(m.GlobalIndex(), true)
+}
+
+// CollapseNode collapses the selected node. If it's already collapsed the
+// cursor moves to its parent.
+func (m *Model) CollapseNode() {
+	row, ok := m.selectedRow()
+	if !ok {
+		return
+	}
+	if row.open && m.NodeExpanded(m.GlobalIndex()) {
+		m.SetNodeExpanded(m.GlobalIndex(), false)
+		return
+	}
+	m.GoToParent()
+}
+
+// GoToParent moves the cursor to the parent of the selected node.
+func (m *Model) GoToParent() {
+	if row, ok := m.selectedRow(); ok && row.parent >= 0 {
+		m.Select(row.parent)
+	}
+}
+
+// TreeDepth returns the depth of the item at the given index in the visible
+// items, 0 being a root. It returns 0 if the list isn't in tree mode.
+func (m Model) TreeDepth(index int) int {
+	if index < 0 || index >= len(m.treeRows) {
+		return 0
+	}
+	return m.treeRows[index].depth
+}
+
+// TreePrefix returns the guide lines and expand indicator to render in front
+// of the first line of the item at the given index in the visible items. It
+// returns an empty string if the list isn't in tree mode.
+func (m Model) TreePrefix(index int) string {
+	return m.treePrefix(index, true)
+}
+
+// TreeIndent returns the guide lines to render in front of the lines after
+// the first of the item at the given index in the visible items, so that
+// they line up with TreePrefix.
+func (m Model) TreeIndent(index int) string {
+	return m.treePrefix(index, false)
+}
+
+func (m Model) treePrefix(index int, first bool) string {
+	if index < 0 || index >= len(m.treeRows) {
+		return ""
+	}
+	row := m.treeRows[index]
+
+	// Guides for the ancestors, continuing down to their later siblings.
+	guides := make([]string, 0, row.depth+1)
+	for p := row.parent; p >= 0 && m.treeRows[p].depth > 0; p = m.treeRows[p].parent {
+		if m.treeRows[p].last {
+			guides = append(guides, "   ")
+		} else {
+			guides = append(guides, "│  ")
+		}
+	}
+	for i, j := 0, len(guides)-1; i < j; i, j = i+1, j-1 {
+		guides[i], guides[j] = guides[j], guides[i]
+	}
+
+	if row.depth > 0 {
+		switch {
+		case first && row.last:
+			guides = append(guides, "└─ ")
+		case first:
+			guides = append(guides, "├─ ")
+		case row.last:
+			guides = append(guides, "   ")
+		default:
+			guides = append(guides, "│  ")
+		}
+	}
+	prefix := m.Styles.TreeGuide.Render(strings.Join(guides, ""))
+
+	switch {
+	case first && row.open:
+		return prefix + m.Styles.TreeExpandedIcon.String() + " "
+	case first && row.hasChildren:
+		return prefix + m.Styles.TreeCollapsedIcon.String() + " "
+	case row.open:
+		return prefix + m.Styles.TreeGuide.Render("│") + " "
+	}
+	return prefix + "  "
+}
+
+// selectedRow returns the tree row of the selected item.
+func (m Model) selectedRow() (treeRow, bool) {
+	i := m.Index()
+	if i < 0 || i >= len(m.treeRows) {
+		return treeRow{}, false
+	}
+	return m.treeRows[i], true
+}
+
+// retree works out which nodes are visible in tree mode: the roots and the
+// children of expanded nodes or, while a filter is applied, the matching
+// nodes and their ancestors.
+func (m *Model) retree() {
+	m.treeItems, m.treeRows = nil, nil
+	if m.tree == nil {
+		return
+	}
+
+	// Find the parent of each node from the depths.
+	parents := make([]int, len(m.tree))
+	var stack []int
+	for i, n := range m.tree {
+		stack = stack[:min(len(stack), n.depth)]
+		parents[i] = -1
+		if len(stack) > 0 {
+			parents[i] = stack[len(stack)-1]
+		}
+		stack = append(stack, i)
+	}
+
+	visible := make([]bool, len(m.tree))
+	var matches map[int]filteredItem
+	if m.filterState != Unfiltered && m.FilterInput.Value() != "" {
+		matches = make(map[int]filteredItem, len(m.filteredItems))
+		for _, fi := range m.filteredItems {
+			matches[fi.index] = fi
+			for i := fi.index; i >= 0 && !visible[i]; i = parents[i] {
+				visible[i] = true
+			}
+		}
+	} else {
+		for i := range m.tree {
+			p := parents[i]
+			visible[i] = p < 0 || (visible[p] && m.tree[p].expanded)
+		}
+	}
+
+	var (
+		items = filteredItems{}
+		rows  []treeRow
+		rowOf = make(map[int]int)
+	)
+	for i, n := range m.tree {
+		if !visible[i] {
+			continue
+		}
+		row := treeRow{
+			depth:       n.depth,
+			parent:      -1,
+			hasChildren: i+1 < len(m.tree) && m.tree[i+1].depth > n.depth,
+		}
+		if p := parents[i]; p >= 0 {
+			row.parent = rowOf[p]
+			rows[row.parent].open = true
+		}
+		rowOf[i] = len(rows)
+		rows = append(rows, row)
+		items = append(items, filteredItem{
+			index:        i,
+			item:         m.items[i],
+			matches:      matches[i].matches,
+			descMatches:  matches[i].descMatches,
+			fieldMatches: matches[i].fieldMatches,
+			rank:         len(items),
+		})
+	}
+
+	// The last visible child of each parent closes its guide line.
+	seen := make(map[int]bool)
+	for i := len(rows) - 1; i >= 0; i-- {
+		if p := rows[i].parent; !seen[p] {
+			seen[p] = true
+			rows[i].last = true
+		}
+	}
+
+	m.treeItems = items
+	m.treeRows = rows
+}
+
+// insertTreeNode keeps the tree in step with an item inserted at the given
+// index, making it a sibling of the node it's inserted before.
+func (m *Model) insertTreeNode(index int) {
+	if m.tree == nil {
+		return
+	}
+	var n treeNode
+	if index >= 0 && index < len(m.tree) {
+		n.depth = m.tree[index].depth
+	}
+	index = max(0, min(index, len(m.tree)))
+	m.tree = append(m.tree, treeNode{})
+	copy(m.tree[index+1:], m.tree[index:])
+	m.tree[index] = n
+}
+
+// removeTreeNode keeps the tree in step with the item at the given index
+// being removed. Its children are moved up to take its place.
+func (m *Model) removeTreeNode(index int) {
+	if index < 0 || index >= len(m.tree) {
+		return
+	}
+	depth := m.tree[index].depth
+	for i := index + 1; i < len(m.tree) && m.tree[i].depth > depth; i++ {
+		m.tree[i].depth--
+	}
+	m.tree = append(m.tree[:index], m.tree[index+1:]...)
+}
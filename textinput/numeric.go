@@ -0,0 +1,248 @@
+package textinput
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumberMode restricts the text input to numeric values.
+type NumberMode int
+
+const (
+	// NumberOff accepts any input. This is the default behavior.
+	NumberOff NumberMode = iota
+
+	// NumberInteger accepts whole numbers, optionally negative.
+	NumberInteger
+
+	// NumberDecimal accepts numbers with an optional fractional part.
+	NumberDecimal
+)
+
+// ErrOutOfRange is returned by validation when a numeric value falls outside
+// of the range set with SetRange.
+var ErrOutOfRange = errors.New("value out of range")
+
+// SetRange sets the minimum and maximum values accepted in number mode. Values
+// outside of the range set Err while typing and are clamped when the input is
+// blurred. The Increment and Decrement bindings never leave the range.
+func (m *Model) SetRange(lo, hi float64) {
+	if hi < lo {
+		lo, hi = hi, lo
+	}
+	m.hasRange = true
+	m.rangeMin = lo
+	m.rangeMax = hi
+}
+
+// ClearRange removes the range set with SetRange.
+func (m *Model) ClearRange() {
+	m.hasRange = false
+	m.rangeMin = 0
+	m.rangeMax = 0
+}
+
+// Int returns the value of the input as an integer. In NumberDecimal mode the
+// fractional part is truncated. An error is returned if the value is not a
+// number.
+func (m Model) Int() (int, error) {
+	s := m.numberString(m.value)
+	if m.NumberMode == NumberDecimal {
+		f, err := strconv.ParseFloat(s, 64)
+		return int(f), err
+	}
+	return strconv.Atoi(s)
+}
+
+// Float returns the value of the input as a float. An error is returned if the
+// value is not a number.
+func (m Model) Float() (float64, error) {
+	return strconv.ParseFloat(m.numberString(m.value), 64)
+}
+
+// numberString returns the given runes as a string with any thousands
+// separators removed in number mode.
+func (m Model) numberString(v []rune) string {
+	if m.NumberMode == NumberOff || m.ThousandsSeparator == 0 {
+		return string(v)
+	}
+	return strings.ReplaceAll(string(v), string(m.ThousandsSeparator), "")
+}
+
+// isNumeric reports whether v is a number, or the beginning of one, that is
+// acceptable for the current number mode.
+func (m Model) isNumeric(v []rune) bool {
+	s := []rune(m.numberString(v))
+	if len(s) > 0 && s[0] == '-' {
+		if m.hasRange && m.rangeMin >= 0 {
+			return false
+		}
+		s = s[1:]
+	}
+
+	seenPoint := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+		case r == '.' && m.NumberMode == NumberDecimal && !seenPoint:
+			seenPoint = true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validateRange returns ErrOutOfRange if v is a number outside of the range
+// set with SetRange.
+func (m Model) validateRange(v []rune) error {
+	if m.NumberMode == NumberOff || !m.hasRange {
+		return nil
+	}
+	f, err := strconv.ParseFloat(m.numberString(v), 64)
+	if err != nil {
+		return nil
+	}
+	if f < m.rangeMin || f > m.rangeMax {
+		return fmt.Errorf("%w: must be between %s and %s", ErrOutOfRange,
+			m.formatFloat(m.rangeMin, -1), m.formatFloat(m.rangeMax, -1))
+	}
+	return nil
+}
+
+// stepNumber adds Step, multiplied by dir, to the current value.
+func (m *Model) stepNumber(dir int) {
+	step := m.Step
+	if step <= 0 {
+		step = 1
+	}
+
+	v, err := m.Float()
+	if err != nil {
+		v = 0
+	}
+	v += step * float64(dir)
+	if m.hasRange {
+		v = math.Max(m.rangeMin, math.Min(m.rangeMax, v))
+	}
+
+	prec := max(decimals(m.formatFloat(step, -1)), decimals(m.numberString(m.value)))
+	m.SetValue(m.formatFloat(v, prec))
+	m.CursorEnd()
+}
+
+// clampNumber clamps the current value to the range set with SetRange.
+func (m *Model) clampNumber() {
+	if m.NumberMode == NumberOff || !m.hasRange {
+		return
+	}
+	v, err := m.Float()
+	if err != nil || (v >= m.rangeMin && v <= m.rangeMax) {
+		return
+	}
+	v = math.Max(m.rangeMin, math.Min(m.rangeMax, v))
+	m.SetValue(m.formatFloat(v, -1))
+	m.CursorEnd()
+}
+
+// formatFloat formats v for the current number mode using prec decimal
+// places. A negative prec uses the smallest number of places necessary.
+func (m Model) formatFloat(v float64, prec int) string {
+	if m.NumberMode == NumberInteger {
+		return strconv.FormatInt(int64(math.Round(v)), 10)
+	}
+	return strconv.FormatFloat(v, 'f', prec, 64)
+}
+
+// formatNumber groups the integer part of the value with ThousandsSeparator,
+// keeping the cursor next to the same digit. Trailing digits are dropped if
+// the grouped value would exceed CharLimit.
+func (m *Model) formatNumber() {
+	sep := m.ThousandsSeparator
+	if m.NumberMode == NumberOff || sep == 0 {
+		return
+	}
+
+	// Count the significant runes before the cursor.
+	n := 0
+	for _, r := range m.value[:m.pos] {
+		if r != sep {
+			n++
+		}
+	}
+
+	digits := []rune(m.numberString(m.value))
+	formatted := groupThousands(digits, sep)
+	for m.CharLimit > 0 && len(formatted) > m.CharLimit {
+		digits = digits[:len(digits)-1]
+		formatted = groupThousands(digits, sep)
+	}
+
+	pos := 0
+	for seen := 0; pos < len(formatted) && seen < n; pos++ {
+		if formatted[pos] != sep {
+			seen++
+		}
+	}
+
+	m.value = formatted
+	m.SetCursor(pos)
+}
+
+// fitsCharLimit reports whether v, once grouped, fits within CharLimit.
+func (m Model) fitsCharLimit(v []rune) bool {
+	if m.CharLimit <= 0 || m.ThousandsSeparator == 0 {
+		return true
+	}
+	grouped := groupThousands([]rune(m.numberString(v)), m.ThousandsSeparator)
+	return len(grouped) <= m.CharLimit
+}
+
+// isGroupSeparator reports whether the rune at i in the value is a thousands
+// separator inserted by number mode.
+func (m Model) isGroupSeparator(i int) bool {
+	return m.NumberMode != NumberOff && m.ThousandsSeparator != 0 &&
+		i >= 0 && i < len(m.value) && m.value[i] == m.ThousandsSeparator
+}
+
+// groupThousands inserts sep between every group of three digits in the
+// integer part of v.
+func groupThousands(v []rune, sep rune) []rune {
+	start := 0
+	if len(v) > 0 && v[0] == '-' {
+		start = 1
+	}
+	end := start
+	for end < len(v) && v[end] != '.' {
+		end++
+	}
+
+	out := make([]rune, 0, len(v)+(end-start)/3)
+	out = append(out, v[:start]...)
+	for i := start; i < end; i++ {
+		if i > start && (end-i)%3 == 0 {
+			out = append(out, sep)
+		}
+		out = append(out, v[i])
+	}
+	return append(out, v[end:]...)
+}
+
+// decimals returns the number of digits after the decimal point in s.
+func decimals(s string) int {
+	if i := strings.IndexRune(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// spliceRunes returns a new slice with ins inserted into v at pos.
+func spliceRunes(v []rune, pos int, ins []rune) []rune {
+	out := make([]rune, 0, len(v)+len(ins))
+	out = append(out, v[:pos]...)
+	out = append(out, ins...)
+	return append(out, v[pos:]...)
+}
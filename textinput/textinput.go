@@ -1,8 +1,11 @@
 package textinput
 
 import (
+	"errors"
 	"reflect"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 	"unicode"
 
@@ -12,16 +15,29 @@ import (
 	"github.com/charmbracelet/bubbles/runeutil"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	rw "github.com/mattn/go-runewidth"
 	"github.com/rivo/uniseg"
 )
 
+// Internal ID management. Used to route peek messages to the right input.
+var lastID int64
+
+func nextID() int {
+	return int(atomic.AddInt64(&lastID, 1))
+}
+
 // Internal messages for clipboard operations.
 type (
 	pasteMsg    string
 	pasteErrMsg struct{ error }
 )
 
+// peekMsg ends the peek at the most recently typed character in password
+// mode.
+type peekMsg struct {
+	id  int
+	tag int
+}
+
 // EchoMode sets the input behavior of the text input field.
 type EchoMode int
 
@@ -41,6 +57,27 @@ const (
 // ValidateFunc is a function that returns an error if the input is invalid.
 type ValidateFunc func(string) error
 
+// ValidationMode determines when input is validated and what happens to
+// input that fails validation.
+type ValidationMode int
+
+const (
+	// ValidationAccept validates the input on every change. Invalid input is
+	// accepted and Err is set. This is the default behavior.
+	ValidationAccept ValidationMode = iota
+
+	// ValidationReject validates the input on every change and discards
+	// changes that fail validation, leaving the previous value in place. A
+	// range set with SetRange isn't enforced while typing, since a partially
+	// typed number can be outside of it. Such values set Err and are clamped
+	// when the input is blurred.
+	ValidationReject
+
+	// ValidationOnBlur validates the input only when it's blurred. Err is
+	// left untouched while the user is typing.
+	ValidationOnBlur
+)
+
 // KeyMap is the key bindings for different actions within the textinput.
 type KeyMap struct {
 	CharacterForward        key.Binding
@@ -59,6 +96,10 @@ type KeyMap struct {
 	AcceptSuggestion        key.Binding
 	NextSuggestion          key.Binding
 	PrevSuggestion          key.Binding
+	Increment               key.Binding
+	Decrement               key.Binding
+	ToggleReveal            key.Binding
+	TokenSeparator          key.Binding
 }
 
 // DefaultKeyMap is the default set of key bindings for navigating and acting
@@ -80,6 +121,10 @@ var DefaultKeyMap = KeyMap{
 	AcceptSuggestion:        key.NewBinding(key.WithKeys("tab")),
 	NextSuggestion:          key.NewBinding(key.WithKeys("down", "ctrl+n")),
 	PrevSuggestion:          key.NewBinding(key.WithKeys("up", "ctrl+p")),
+	Increment:               key.NewBinding(key.WithKeys("up")),
+	Decrement:               key.NewBinding(key.WithKeys("down")),
+	ToggleReveal:            key.NewBinding(key.WithKeys("ctrl+r")),
+	TokenSeparator:          key.NewBinding(key.WithKeys("enter", ",")),
 }
 
 // Model is the Bubble Tea model for this text input element.
@@ -96,6 +141,11 @@ type Model struct {
 	// Deprecated: use [cursor.BlinkSpeed] instead.
 	BlinkSpeed time.Duration
 
+	// PeekDuration is how long the most recently typed character stays
+	// visible in EchoPassword mode before it's masked. If 0 or less typed
+	// characters are masked immediately.
+	PeekDuration time.Duration
+
 	// Styles. These will be applied as inline styles.
 	//
 	// For an introduction to styling with Lip Gloss see:
@@ -104,6 +154,11 @@ type Model struct {
 	TextStyle        lipgloss.Style
 	PlaceholderStyle lipgloss.Style
 	CompletionStyle  lipgloss.Style
+	ErrorStyle       lipgloss.Style
+
+	// Styles for tokens in TokenMode.
+	TokenStyle         lipgloss.Style
+	SelectedTokenStyle lipgloss.Style
 
 	// Deprecated: use Cursor.Style instead.
 	CursorStyle lipgloss.Style
@@ -120,9 +175,21 @@ type Model struct {
 	// KeyMap encodes the keybindings recognized by the widget.
 	KeyMap KeyMap
 
+	// The ID of this model as it relates to other text inputs.
+	id int
+
 	// Underlying text value.
 	value []rune
 
+	// revealed shows the actual characters in EchoPassword mode.
+	revealed bool
+
+	// State for peeking at the most recently typed character in
+	// EchoPassword mode. peekTag identifies the peek we're expecting to end.
+	peeking bool
+	peekPos int
+	peekTag int
+
 	// focus indicates whether user input focus should be on this input
 	// component. When false, ignore keyboard input and hide the cursor.
 	focus bool
@@ -141,9 +208,52 @@ type Model struct {
 	// input is considered valid.
 	Validate ValidateFunc
 
+	// ValidationMode determines when Validate is called and whether invalid
+	// input is accepted. See ValidationMode.
+	ValidationMode ValidationMode
+
+	// ShowError renders Err, styled with ErrorStyle, on the line below the
+	// input when it's set.
+	ShowError bool
+
+	// TokenMode turns the input into a field of tokens, such as tags or
+	// recipients. The TokenSeparator binding turns the current text into a
+	// token, rendered with TokenStyle in front of the text. Width only
+	// applies to the text being typed. See Tokens.
+	TokenMode bool
+
+	// Tokens entered in TokenMode and the token selected with the
+	// CharacterBackward and CharacterForward bindings, if any.
+	tokens        []string
+	tokenIndex    int
+	tokenSelected bool
+
 	// rune sanitizer for input.
 	rsan runeutil.Sanitizer
 
+	// NumberMode restricts the input to numeric values. When set, input that
+	// would not form a valid number is ignored and the Increment and
+	// Decrement bindings step the value. See also SetRange.
+	//
+	// Increment and Decrement share keys with NextSuggestion and
+	// PrevSuggestion by default. While suggestions match the value, the
+	// suggestion bindings are enabled and the stepping ones aren't, and the
+	// other way around otherwise.
+	NumberMode NumberMode
+
+	// Step is the amount the Increment and Decrement bindings add to or
+	// subtract from a numeric value. If 0 or less a step of 1 is used.
+	Step float64
+
+	// ThousandsSeparator, if set, is used to group the integer part of a
+	// numeric value, e.g. 1,234,567. It's ignored unless NumberMode is set.
+	ThousandsSeparator rune
+
+	// Bounds for numeric input, as set by SetRange.
+	hasRange bool
+	rangeMin float64
+	rangeMax float64
+
 	// Should the input suggest to complete
 	ShowSuggestions bool
 
@@ -163,9 +273,19 @@ func New() Model {
 		PlaceholderStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
 		ShowSuggestions:  false,
 		CompletionStyle:  lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+		ErrorStyle:       lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
 		Cursor:           cursor.New(),
 		KeyMap:           DefaultKeyMap,
-
+		TokenStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("252")).
+			Background(lipgloss.Color("237")).
+			Padding(0, 1),
+		SelectedTokenStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("230")).
+			Background(lipgloss.Color("62")).
+			Padding(0, 1),
+
+		id:          nextID(),
 		suggestions: [][]rune{},
 		value:       nil,
 		focus:       false,
@@ -185,6 +305,7 @@ func (m *Model) SetValue(s string) {
 	runes := m.san().Sanitize([]rune(s))
 	err := m.validate(runes)
 	m.setValueInternal(runes, err)
+	m.formatNumber()
 }
 
 func (m *Model) setValueInternal(runes []rune, err error) {
@@ -214,9 +335,11 @@ func (m Model) Position() int {
 }
 
 // SetCursor moves the cursor to the given position. If the position is
-// out of bounds the cursor will be moved to the start or end accordingly.
+// out of bounds the cursor will be moved to the start or end accordingly. If
+// the position falls within a grapheme cluster, such as an emoji sequence or
+// a letter with combining marks, the cursor is moved to the start of it.
 func (m *Model) SetCursor(pos int) {
-	m.pos = clamp(pos, 0, len(m.value))
+	m.pos = clusterStart(graphemeBounds(m.value), clamp(pos, 0, len(m.value)))
 	m.handleOverflow()
 }
 
@@ -243,15 +366,36 @@ func (m *Model) Focus() tea.Cmd {
 }
 
 // Blur removes the focus state on the model.  When the model is blurred it can
-// not receive keyboard input and the cursor will be hidden.
+// not receive keyboard input and the cursor will be hidden. If a numeric range
+// is set, an out of range value is clamped to it. With ValidationOnBlur, this
+// is also when the input is validated.
 func (m *Model) Blur() {
 	m.focus = false
+	m.peeking = false
 	m.Cursor.Blur()
+	m.clampNumber()
+	if m.ValidationMode == ValidationOnBlur {
+		m.Err = m.validate(m.value)
+	}
 }
 
-// Reset sets the input to its default state with no input.
+// Revealed returns whether the input is currently shown unmasked in
+// EchoPassword mode.
+func (m Model) Revealed() bool {
+	return m.revealed
+}
+
+// SetRevealed shows or masks the input in EchoPassword mode. It has no effect
+// in other echo modes.
+func (m *Model) SetRevealed(v bool) {
+	m.revealed = v
+}
+
+// Reset sets the input to its default state with no input and no tokens.
 func (m *Model) Reset() {
 	m.value = nil
+	m.tokens = nil
+	m.tokenSelected = false
 	m.SetCursor(0)
 }
 
@@ -298,6 +442,15 @@ func (m *Model) insertRunesFromUserInput(v []rune) {
 		}
 	}
 
+	// In number mode drop input that would not result in a valid number, or
+	// that wouldn't fit once grouped.
+	if m.NumberMode != NumberOff {
+		v := spliceRunes(m.value, m.pos, paste)
+		if !m.isNumeric(v) || !m.fitsCharLimit(v) {
+			return
+		}
+	}
+
 	// Stuff before and after the cursor
 	head := m.value[:m.pos]
 	tailSrc := m.value[m.pos:]
@@ -318,7 +471,7 @@ func (m *Model) insertRunesFromUserInput(v []rune) {
 
 	// Put it all back together
 	value := append(head, tail...)
-	inputErr := m.validate(value)
+	inputErr := m.validateInput(value)
 	m.setValueInternal(value, inputErr)
 }
 
@@ -334,43 +487,45 @@ func (m *Model) handleOverflow() {
 	// Correct right offset if we've deleted characters
 	m.offsetRight = min(m.offsetRight, len(m.value))
 
+	bounds := graphemeBounds(m.value)
+
 	if m.pos < m.offset {
 		m.offset = m.pos
 
 		w := 0
-		i := 0
-		runes := m.value[m.offset:]
+		i := m.offset
 
-		for i < len(runes) && w <= m.Width {
-			w += rw.RuneWidth(runes[i])
+		for i < len(m.value) && w <= m.Width {
+			next := nextBoundary(bounds, i)
+			w += uniseg.StringWidth(string(m.value[i:next]))
 			if w <= m.Width+1 {
-				i++
+				i = next
 			}
 		}
 
-		m.offsetRight = m.offset + i
+		m.offsetRight = i
 	} else if m.pos >= m.offsetRight {
 		m.offsetRight = m.pos
 
 		w := 0
-		runes := m.value[:m.offsetRight]
-		i := len(runes) - 1
+		i := m.offsetRight
 
 		for i > 0 && w < m.Width {
-			w += rw.RuneWidth(runes[i])
+			prev := prevBoundary(bounds, i)
+			w += uniseg.StringWidth(string(m.value[prev:i]))
 			if w <= m.Width {
-				i--
+				i = prev
 			}
 		}
 
-		m.offset = m.offsetRight - (len(runes) - 1 - i)
+		m.offset = i
 	}
 }
 
 // deleteBeforeCursor deletes all text before the cursor.
 func (m *Model) deleteBeforeCursor() {
 	m.value = m.value[m.pos:]
-	m.Err = m.validate(m.value)
+	m.Err = m.validateInput(m.value)
 	m.offset = 0
 	m.SetCursor(0)
 }
@@ -380,7 +535,7 @@ func (m *Model) deleteBeforeCursor() {
 // masked input.
 func (m *Model) deleteAfterCursor() {
 	m.value = m.value[:m.pos]
-	m.Err = m.validate(m.value)
+	m.Err = m.validateInput(m.value)
 	m.SetCursor(len(m.value))
 }
 
@@ -390,43 +545,15 @@ func (m *Model) deleteWordBackward() {
 		return
 	}
 
-	if m.EchoMode != EchoNormal {
+	if m.masked() {
 		m.deleteBeforeCursor()
 		return
 	}
 
-	// Linter note: it's critical that we acquire the initial cursor position
-	// here prior to altering it via SetCursor() below. As such, moving this
-	// call into the corresponding if clause does not apply here.
-	oldPos := m.pos //nolint:ifshort
-
-	m.SetCursor(m.pos - 1)
-	for unicode.IsSpace(m.value[m.pos]) {
-		if m.pos <= 0 {
-			break
-		}
-		// ignore series of whitespace before cursor
-		m.SetCursor(m.pos - 1)
-	}
-
-	for m.pos > 0 {
-		if !unicode.IsSpace(m.value[m.pos]) {
-			m.SetCursor(m.pos - 1)
-		} else {
-			if m.pos > 0 {
-				// keep the previous space
-				m.SetCursor(m.pos + 1)
-			}
-			break
-		}
-	}
-
-	if oldPos > len(m.value) {
-		m.value = m.value[:m.pos]
-	} else {
-		m.value = append(m.value[:m.pos], m.value[oldPos:]...)
-	}
-	m.Err = m.validate(m.value)
+	start := m.wordStart(m.pos)
+	m.value = append(m.value[:start], m.value[m.pos:]...)
+	m.Err = m.validateInput(m.value)
+	m.SetCursor(start)
 }
 
 // deleteWordForward deletes the word right to the cursor. If input is masked
@@ -437,38 +564,15 @@ func (m *Model) deleteWordForward() {
 		return
 	}
 
-	if m.EchoMode != EchoNormal {
+	if m.masked() {
 		m.deleteAfterCursor()
 		return
 	}
 
-	oldPos := m.pos
-	m.SetCursor(m.pos + 1)
-	for unicode.IsSpace(m.value[m.pos]) {
-		// ignore series of whitespace after cursor
-		m.SetCursor(m.pos + 1)
-
-		if m.pos >= len(m.value) {
-			break
-		}
-	}
-
-	for m.pos < len(m.value) {
-		if !unicode.IsSpace(m.value[m.pos]) {
-			m.SetCursor(m.pos + 1)
-		} else {
-			break
-		}
-	}
-
-	if m.pos > len(m.value) {
-		m.value = m.value[:oldPos]
-	} else {
-		m.value = append(m.value[:oldPos], m.value[m.pos:]...)
-	}
-	m.Err = m.validate(m.value)
-
-	m.SetCursor(oldPos)
+	end := m.wordEnd(m.pos)
+	m.value = append(m.value[:m.pos], m.value[end:]...)
+	m.Err = m.validateInput(m.value)
+	m.SetCursor(m.pos)
 }
 
 // wordBackward moves the cursor one word to the left. If input is masked, move
@@ -478,29 +582,12 @@ func (m *Model) wordBackward() {
 		return
 	}
 
-	if m.EchoMode != EchoNormal {
+	if m.masked() {
 		m.CursorStart()
 		return
 	}
 
-	i := m.pos - 1
-	for i >= 0 {
-		if unicode.IsSpace(m.value[i]) {
-			m.SetCursor(m.pos - 1)
-			i--
-		} else {
-			break
-		}
-	}
-
-	for i >= 0 {
-		if !unicode.IsSpace(m.value[i]) {
-			m.SetCursor(m.pos - 1)
-			i--
-		} else {
-			break
-		}
-	}
+	m.SetCursor(m.wordStart(m.pos))
 }
 
 // wordForward moves the cursor one word to the right. If the input is masked,
@@ -510,34 +597,79 @@ func (m *Model) wordForward() {
 		return
 	}
 
-	if m.EchoMode != EchoNormal {
+	if m.masked() {
 		m.CursorEnd()
 		return
 	}
 
-	i := m.pos
-	for i < len(m.value) {
-		if unicode.IsSpace(m.value[i]) {
-			m.SetCursor(m.pos + 1)
-			i++
-		} else {
-			break
-		}
+	m.SetCursor(m.wordEnd(m.pos))
+}
+
+// wordStart returns the position of the start of the word before pos,
+// skipping any whitespace in between.
+func (m Model) wordStart(pos int) int {
+	i := pos
+	for i > 0 && unicode.IsSpace(m.value[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(m.value[i-1]) {
+		i--
 	}
+	return clusterStart(graphemeBounds(m.value), i)
+}
 
-	for i < len(m.value) {
-		if !unicode.IsSpace(m.value[i]) {
-			m.SetCursor(m.pos + 1)
-			i++
-		} else {
-			break
-		}
+// wordEnd returns the position of the end of the word after pos, skipping
+// any whitespace in between.
+func (m Model) wordEnd(pos int) int {
+	i := pos
+	for i < len(m.value) && unicode.IsSpace(m.value[i]) {
+		i++
 	}
+	for i < len(m.value) && !unicode.IsSpace(m.value[i]) {
+		i++
+	}
+	bounds := graphemeBounds(m.value)
+	if i > clusterStart(bounds, i) {
+		i = nextBoundary(bounds, i)
+	}
+	return i
+}
+
+// masked returns whether the input is currently hidden from view.
+func (m Model) masked() bool {
+	return m.EchoMode != EchoNormal && !(m.EchoMode == EchoPassword && m.revealed)
+}
+
+// echoRunes returns the given runes, which start at position start in the
+// value, transformed for the current echo mode. A character being peeked at
+// is left unmasked.
+func (m Model) echoRunes(v []rune, start int) string {
+	i := m.peekPos - start
+	if m.EchoMode != EchoPassword || !m.peeking || i < 0 || i >= len(v) {
+		return m.echoTransform(string(v))
+	}
+	return m.echoTransform(string(v[:i])) + string(v[i]) + m.echoTransform(string(v[i+1:]))
+}
+
+// peek briefly shows the character at pos in EchoPassword mode. It returns a
+// command that masks it again once PeekDuration has passed.
+func (m *Model) peek(pos int) tea.Cmd {
+	m.peeking = true
+	m.peekPos = pos
+	m.peekTag++
+
+	id, tag := m.id, m.peekTag
+	return tea.Tick(m.PeekDuration, func(time.Time) tea.Msg {
+		return peekMsg{id: id, tag: tag}
+	})
 }
 
 func (m Model) echoTransform(v string) string {
 	switch m.EchoMode {
 	case EchoPassword:
+		if m.revealed {
+			return v
+		}
 		return strings.Repeat(string(m.EchoCharacter), uniseg.StringWidth(v))
 	case EchoNone:
 		return ""
@@ -554,6 +686,8 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		return m, nil
 	}
 
+	m.updateKeybindings()
+
 	// Need to check for completion before, because key is configurable and might be double assigned
 	keyMsg, ok := msg.(tea.KeyMsg)
 	if ok && key.Matches(keyMsg, m.KeyMap.AcceptSuggestion) {
@@ -563,42 +697,71 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 	}
 
+	// In token mode some keys act on tokens rather than the text.
+	if ok && m.TokenMode && m.updateTokens(keyMsg) {
+		return m, nil
+	}
+
 	// Let's remember where the position of the cursor currently is so that if
 	// the cursor position changes, we can reset the blink.
 	oldPos := m.pos //nolint
 
+	// Keep the current value and error around so that edits can be undone
+	// when they fail validation.
+	oldValue := append([]rune(nil), m.value...)
+	oldErr := m.Err
+
+	var peekCmd tea.Cmd
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.peeking = false
+
 		switch {
+		case m.EchoMode == EchoPassword && key.Matches(msg, m.KeyMap.ToggleReveal):
+			m.revealed = !m.revealed
+		case m.NumberMode != NumberOff && key.Matches(msg, m.KeyMap.Increment):
+			m.stepNumber(1)
+		case m.NumberMode != NumberOff && key.Matches(msg, m.KeyMap.Decrement):
+			m.stepNumber(-1)
 		case key.Matches(msg, m.KeyMap.DeleteWordBackward):
 			m.deleteWordBackward()
 		case key.Matches(msg, m.KeyMap.DeleteCharacterBackward):
-			m.Err = nil
 			if len(m.value) > 0 {
-				m.value = append(m.value[:max(0, m.pos-1)], m.value[m.pos:]...)
-				m.Err = m.validate(m.value)
-				if m.pos > 0 {
-					m.SetCursor(m.pos - 1)
+				bounds := graphemeBounds(m.value)
+				start := prevBoundary(bounds, m.pos)
+				if m.isGroupSeparator(start) {
+					// Separators are put back when the number is grouped
+					// again, so delete the digit before it instead.
+					start = prevBoundary(bounds, start)
 				}
+				m.value = append(m.value[:start], m.value[m.pos:]...)
+				m.Err = m.validateInput(m.value)
+				m.SetCursor(start)
 			}
 		case key.Matches(msg, m.KeyMap.WordBackward):
 			m.wordBackward()
 		case key.Matches(msg, m.KeyMap.CharacterBackward):
 			if m.pos > 0 {
-				m.SetCursor(m.pos - 1)
+				m.SetCursor(prevBoundary(graphemeBounds(m.value), m.pos))
 			}
 		case key.Matches(msg, m.KeyMap.WordForward):
 			m.wordForward()
 		case key.Matches(msg, m.KeyMap.CharacterForward):
 			if m.pos < len(m.value) {
-				m.SetCursor(m.pos + 1)
+				m.SetCursor(nextBoundary(graphemeBounds(m.value), m.pos))
 			}
 		case key.Matches(msg, m.KeyMap.LineStart):
 			m.CursorStart()
 		case key.Matches(msg, m.KeyMap.DeleteCharacterForward):
 			if len(m.value) > 0 && m.pos < len(m.value) {
-				m.value = append(m.value[:m.pos], m.value[m.pos+1:]...)
-				m.Err = m.validate(m.value)
+				bounds := graphemeBounds(m.value)
+				end := nextBoundary(bounds, m.pos)
+				if m.isGroupSeparator(m.pos) {
+					end = nextBoundary(bounds, end)
+				}
+				m.value = append(m.value[:m.pos], m.value[end:]...)
+				m.Err = m.validateInput(m.value)
 			}
 		case key.Matches(msg, m.KeyMap.LineEnd):
 			m.CursorEnd()
@@ -619,18 +782,34 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			m.insertRunesFromUserInput(msg.Runes)
 		}
 
+		m.rejectInvalid(oldValue, oldPos, oldErr)
+
+		// Briefly show a single typed character in password mode.
+		typed := len(msg.Runes) == 1 && len(m.value) == len(oldValue)+1 && m.pos == oldPos+1
+		if typed && m.EchoMode == EchoPassword && !m.revealed && m.PeekDuration > 0 {
+			peekCmd = m.peek(m.pos - 1)
+		}
+
 		// Check again if can be completed
 		// because value might be something that does not match the completion prefix
 		m.updateSuggestions()
+		m.formatNumber()
 
 	case pasteMsg:
 		m.insertRunesFromUserInput([]rune(msg))
+		m.rejectInvalid(oldValue, oldPos, oldErr)
+		m.formatNumber()
 
 	case pasteErrMsg:
 		m.Err = msg
+
+	case peekMsg:
+		if msg.id == m.id && msg.tag == m.peekTag {
+			m.peeking = false
+		}
 	}
 
-	var cmds []tea.Cmd
+	cmds := []tea.Cmd{peekCmd}
 	var cmd tea.Cmd
 
 	m.Cursor, cmd = m.Cursor.Update(msg)
@@ -648,37 +827,45 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 // View renders the textinput in its current state.
 func (m Model) View() string {
 	// Placeholder text
-	if len(m.value) == 0 && m.Placeholder != "" {
-		return m.placeholderView()
+	if len(m.value) == 0 && len(m.tokens) == 0 && m.Placeholder != "" {
+		return m.placeholderView() + m.errorView()
 	}
 
 	styleText := m.TextStyle.Inline(true).Render
 
+	// cursorView renders char under the cursor. While a token is selected
+	// the text cursor is hidden, so char is rendered as plain text.
+	cursorView := func(char string) string {
+		if m.tokenSelected {
+			return m.Cursor.TextStyle.Inline(true).Render(char)
+		}
+		m.Cursor.SetChar(char)
+		return m.Cursor.View()
+	}
+
 	value := m.value[m.offset:m.offsetRight]
 	pos := max(0, m.pos-m.offset)
-	v := styleText(m.echoTransform(string(value[:pos])))
+	v := styleText(m.echoRunes(value[:pos], m.offset))
 
 	if pos < len(value) {
-		char := m.echoTransform(string(value[pos]))
-		m.Cursor.SetChar(char)
-		v += m.Cursor.View()                                   // cursor and text under it
-		v += styleText(m.echoTransform(string(value[pos+1:]))) // text after cursor
+		// The cursor covers the whole grapheme cluster under it.
+		end := min(len(value), nextBoundary(graphemeBounds(m.value), m.pos)-m.offset)
+		char := m.echoRunes(value[pos:end], m.offset+pos)
+		v += cursorView(char)                                  // cursor and text under it
+		v += styleText(m.echoRunes(value[end:], m.offset+end)) // text after cursor
 		v += m.completionView(0)                               // suggested completion
 	} else {
 		if m.canAcceptSuggestion() {
 			suggestion := m.matchedSuggestions[m.currentSuggestionIndex]
 			if len(value) < len(suggestion) {
 				m.Cursor.TextStyle = m.CompletionStyle
-				m.Cursor.SetChar(m.echoTransform(string(suggestion[pos])))
-				v += m.Cursor.View()
+				v += cursorView(m.echoTransform(string(suggestion[pos])))
 				v += m.completionView(1)
 			} else {
-				m.Cursor.SetChar(" ")
-				v += m.Cursor.View()
+				v += cursorView(" ")
 			}
 		} else {
-			m.Cursor.SetChar(" ")
-			v += m.Cursor.View()
+			v += cursorView(" ")
 		}
 	}
 
@@ -693,7 +880,15 @@ func (m Model) View() string {
 		v += styleText(strings.Repeat(" ", padding))
 	}
 
-	return m.PromptStyle.Render(m.Prompt) + v
+	return m.PromptStyle.Render(m.Prompt) + m.tokensView() + v + m.errorView()
+}
+
+// errorView returns the error line rendered below the input, if any.
+func (m Model) errorView() string {
+	if !m.ShowError || m.Err == nil {
+		return ""
+	}
+	return "\n" + m.ErrorStyle.Inline(true).Render(m.Err.Error())
 }
 
 // placeholderView returns the prompt and placeholder view, if any.
@@ -749,6 +944,47 @@ func Paste() tea.Msg {
 	return pasteMsg(str)
 }
 
+// graphemeBounds returns the positions at which the grapheme clusters in v
+// start, followed by len(v).
+func graphemeBounds(v []rune) []int {
+	bounds := []int{0}
+	if len(v) == 0 {
+		return bounds
+	}
+
+	n := 0
+	g := uniseg.NewGraphemes(string(v))
+	for g.Next() {
+		n += len(g.Runes())
+		bounds = append(bounds, n)
+	}
+	return bounds
+}
+
+// clusterStart returns the start of the grapheme cluster containing pos.
+func clusterStart(bounds []int, pos int) int {
+	i := sort.SearchInts(bounds, pos)
+	if i < len(bounds) && bounds[i] == pos {
+		return pos
+	}
+	return bounds[max(0, i-1)]
+}
+
+// prevBoundary returns the start of the grapheme cluster before pos.
+func prevBoundary(bounds []int, pos int) int {
+	i := sort.SearchInts(bounds, pos)
+	return bounds[max(0, i-1)]
+}
+
+// nextBoundary returns the end of the grapheme cluster at pos.
+func nextBoundary(bounds []int, pos int) int {
+	i := sort.SearchInts(bounds, pos+1)
+	if i >= len(bounds) {
+		return bounds[len(bounds)-1]
+	}
+	return bounds[i]
+}
+
 func clamp(v, low, high int) int {
 	if high < low {
 		low, high = high, low
@@ -851,6 +1087,16 @@ func (m *Model) canAcceptSuggestion() bool {
 	return len(m.matchedSuggestions) > 0
 }
 
+// updateKeybindings enables either the suggestion or the number stepping
+// bindings, so that they can share keys.
+func (m *Model) updateKeybindings() {
+	stepping := m.NumberMode != NumberOff && !(m.ShowSuggestions && m.canAcceptSuggestion())
+	m.KeyMap.Increment.SetEnabled(stepping)
+	m.KeyMap.Decrement.SetEnabled(stepping)
+	m.KeyMap.NextSuggestion.SetEnabled(!stepping)
+	m.KeyMap.PrevSuggestion.SetEnabled(!stepping)
+}
+
 // updateSuggestions refreshes the list of matching suggestions.
 func (m *Model) updateSuggestions() {
 	if !m.ShowSuggestions {
@@ -866,6 +1112,11 @@ func (m *Model) updateSuggestions() {
 	for _, s := range m.suggestions {
 		suggestion := string(s)
 
+		// Don't suggest what has already been entered as a token.
+		if m.TokenMode && m.hasToken(suggestion) {
+			continue
+		}
+
 		if strings.HasPrefix(strings.ToLower(suggestion), strings.ToLower(string(m.value))) {
 			matches = append(matches, []rune(suggestion))
 		}
@@ -893,9 +1144,37 @@ func (m *Model) previousSuggestion() {
 	}
 }
 
+// validateInput validates v in response to user input, honoring the
+// ValidationMode.
+func (m Model) validateInput(v []rune) error {
+	if m.ValidationMode == ValidationOnBlur {
+		return m.Err
+	}
+	return m.validate(v)
+}
+
+// rejectInvalid restores the given value, cursor position and error if the
+// current value failed validation and ValidationReject is set. Values out of
+// the numeric range are kept, as they may be the start of one within it.
+func (m *Model) rejectInvalid(value []rune, pos int, err error) {
+	if m.ValidationMode != ValidationReject || m.Err == nil || reflect.DeepEqual(value, m.value) {
+		return
+	}
+	if errors.Is(m.Err, ErrOutOfRange) {
+		return
+	}
+	m.value = value
+	m.Err = err
+	m.SetCursor(pos)
+}
+
+// validate checks v with Validate and then against the numeric range, so
+// that ErrOutOfRange is only returned for values that pass Validate.
 func (m Model) validate(v []rune) error {
 	if m.Validate != nil {
-		return m.Validate(string(v))
+		if err := m.Validate(string(v)); err != nil {
+			return err
+		}
 	}
-	return nil
+	return m.validateRange(v)
 }
@@ -1,7 +1,13 @@
 package textinput
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func Test_CurrentSuggestion(t *testing.T) {
@@ -30,3 +36,300 @@ func Test_CurrentSuggestion(t *testing.T) {
 		t.Fatalf("Error: expected first suggestion but was %s", suggestion)
 	}
 }
+
+func Test_NumberMode(t *testing.T) {
+	textinput := New()
+	textinput.NumberMode = NumberDecimal
+	textinput.ThousandsSeparator = ','
+	textinput.SetRange(-10, 5000)
+	textinput.Focus()
+
+	for _, r := range "12a3-4.5.6" {
+		textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if v := textinput.Value(); v != "1,234.56" {
+		t.Fatalf("Error: expected value to be 1,234.56 but was %s", v)
+	}
+
+	f, err := textinput.Float()
+	if err != nil || f != 1234.56 {
+		t.Fatalf("Error: expected 1234.56 but was %v (%v)", f, err)
+	}
+
+	textinput.SetValue("4999")
+	textinput.Step = 0.5
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if v := textinput.Value(); v != "4,999.5" {
+		t.Fatalf("Error: expected value to be 4,999.5 but was %s", v)
+	}
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyUp})
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if i, _ := textinput.Int(); i != 5000 {
+		t.Fatalf("Error: expected value to be clamped to 5000 but was %d", i)
+	}
+
+	textinput.SetValue("99999")
+	if !errors.Is(textinput.Err, ErrOutOfRange) {
+		t.Fatalf("Error: expected ErrOutOfRange but got %v", textinput.Err)
+	}
+	textinput.Blur()
+	if v := textinput.Value(); v != "5,000" {
+		t.Fatalf("Error: expected value to be clamped on blur but was %s", v)
+	}
+
+	// Backspacing over a separator deletes the digit before it.
+	textinput.Focus()
+	textinput.SetValue("12,345")
+	textinput.SetCursor(3)
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if v, pos := textinput.Value(), textinput.Position(); v != "1,345" || pos != 1 {
+		t.Fatalf("Error: expected 1,345 with the cursor at 1 but got %s at %d", v, pos)
+	}
+
+	// Separators count towards the character limit.
+	textinput = New()
+	textinput.NumberMode = NumberInteger
+	textinput.ThousandsSeparator = ','
+	textinput.CharLimit = 5
+	textinput.Focus()
+	for _, r := range "123456" {
+		textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if v := textinput.Value(); v != "1,234" {
+		t.Fatalf("Error: expected value to be limited to 1,234 but was %s", v)
+	}
+
+	// Suggestions that match take the up and down keys from stepping.
+	textinput = New()
+	textinput.NumberMode = NumberInteger
+	textinput.ShowSuggestions = true
+	textinput.SetSuggestions([]string{"100", "150"})
+	textinput.Focus()
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if v, s := textinput.Value(), textinput.CurrentSuggestion(); v != "1" || s != "150" {
+		t.Fatalf("Error: expected the next suggestion, got %s (%s)", v, s)
+	}
+	textinput.SetValue("7")
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'0'}})
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if v := textinput.Value(); v != "69" {
+		t.Fatalf("Error: expected the value to step down to 69 but was %s", v)
+	}
+
+	// Outside of number mode, separators are plain text.
+	textinput = New()
+	textinput.ThousandsSeparator = ','
+	textinput.SetValue("1,2")
+	if _, err := textinput.Int(); err == nil {
+		t.Fatal("Error: expected 1,2 not to be a number outside of number mode")
+	}
+}
+
+func Test_ValidationMode(t *testing.T) {
+	errTooLong := errors.New("too long")
+	validate := func(s string) error {
+		if len(s) > 3 {
+			return errTooLong
+		}
+		return nil
+	}
+	typeString := func(m Model, s string) Model {
+		for _, r := range s {
+			m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+		return m
+	}
+
+	textinput := New()
+	textinput.Validate = validate
+	textinput.ShowError = true
+	textinput.Focus()
+	textinput = typeString(textinput, "abcd")
+	if textinput.Value() != "abcd" || textinput.Err != errTooLong {
+		t.Fatalf("Error: expected invalid input to be accepted, got %q (%v)", textinput.Value(), textinput.Err)
+	}
+	if !strings.Contains(textinput.View(), "\n"+errTooLong.Error()) {
+		t.Fatalf("Error: expected error line in view, got %q", textinput.View())
+	}
+
+	textinput = New()
+	textinput.Validate = validate
+	textinput.ValidationMode = ValidationReject
+	textinput.Focus()
+	textinput = typeString(textinput, "abcd")
+	if textinput.Value() != "abc" || textinput.Err != nil {
+		t.Fatalf("Error: expected invalid input to be rejected, got %q (%v)", textinput.Value(), textinput.Err)
+	}
+
+	textinput = New()
+	textinput.Validate = validate
+	textinput.ValidationMode = ValidationOnBlur
+	textinput.Focus()
+	textinput = typeString(textinput, "abcd")
+	if textinput.Err != nil {
+		t.Fatalf("Error: expected no validation before blur, got %v", textinput.Err)
+	}
+	textinput.Blur()
+	if textinput.Err != errTooLong {
+		t.Fatalf("Error: expected validation on blur, got %v", textinput.Err)
+	}
+
+	// Deleting leaves the error until the next blur, as typing does.
+	textinput.Focus()
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if textinput.Err != errTooLong {
+		t.Fatalf("Error: expected the error to stay until blur, got %v", textinput.Err)
+	}
+	textinput.Blur()
+	if textinput.Err != nil {
+		t.Fatalf("Error: expected no error after blur, got %v", textinput.Err)
+	}
+
+	// Numbers that need several digits to reach the range can be typed, and
+	// are clamped on blur if they end up outside of it.
+	textinput = New()
+	textinput.NumberMode = NumberInteger
+	textinput.SetRange(10, 100)
+	textinput.Validate = validate
+	textinput.ValidationMode = ValidationReject
+	textinput.Focus()
+	textinput = typeString(textinput, "5")
+	if textinput.Value() != "5" || !errors.Is(textinput.Err, ErrOutOfRange) {
+		t.Fatalf("Error: expected a partial number to be kept, got %q (%v)", textinput.Value(), textinput.Err)
+	}
+	textinput = typeString(textinput, "0")
+	if textinput.Value() != "50" || textinput.Err != nil {
+		t.Fatalf("Error: expected 50 to be valid, got %q (%v)", textinput.Value(), textinput.Err)
+	}
+	textinput = typeString(textinput, "00")
+	if textinput.Value() != "500" {
+		t.Fatalf("Error: expected Validate to reject a fourth digit, got %q", textinput.Value())
+	}
+	textinput.Blur()
+	if textinput.Value() != "100" {
+		t.Fatalf("Error: expected the value to be clamped on blur, got %q", textinput.Value())
+	}
+}
+
+func Test_PasswordRevealAndPeek(t *testing.T) {
+	textinput := New()
+	textinput.EchoMode = EchoPassword
+	textinput.PeekDuration = time.Second
+	textinput.Focus()
+
+	var cmd tea.Cmd
+	for _, r := range "secret" {
+		textinput, cmd = textinput.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if cmd == nil {
+		t.Fatal("Error: expected a command to end the peek")
+	}
+	if v := textinput.View(); !strings.Contains(v, "*****t") {
+		t.Fatalf("Error: expected the last character to be visible, got %q", v)
+	}
+
+	textinput, _ = textinput.Update(peekMsg{id: textinput.id, tag: textinput.peekTag})
+	if v := textinput.View(); !strings.Contains(v, "******") {
+		t.Fatalf("Error: expected the value to be masked, got %q", v)
+	}
+
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if !textinput.Revealed() || !strings.Contains(textinput.View(), "secret") {
+		t.Fatalf("Error: expected the value to be revealed, got %q", textinput.View())
+	}
+}
+
+func Test_GraphemeClusters(t *testing.T) {
+	const family = "👨‍👩‍👧"
+
+	tests := []struct {
+		name     string
+		value    string
+		keys     []tea.KeyType
+		expected string
+		pos      int
+	}{
+		{"backspace family emoji", "a" + family, []tea.KeyType{tea.KeyBackspace}, "a", 1},
+		{"backspace flag", "🇯🇵🇫🇷", []tea.KeyType{tea.KeyBackspace}, "🇯🇵", 2},
+		{"backspace combining accent", "café", []tea.KeyType{tea.KeyBackspace}, "caf", 3},
+		{"backspace devanagari", "नमस्ते", []tea.KeyType{tea.KeyBackspace}, "नमस्", 4},
+		{"left over family emoji", family + "b", []tea.KeyType{tea.KeyLeft, tea.KeyLeft}, family + "b", 0},
+		{"delete family emoji", family + "b", []tea.KeyType{tea.KeyHome, tea.KeyDelete}, "b", 0},
+		{"right over devanagari", "नमस्ते", []tea.KeyType{tea.KeyHome, tea.KeyRight, tea.KeyRight, tea.KeyRight}, "नमस्ते", 4},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			textinput := New()
+			textinput.Focus()
+			textinput.SetValue(tc.value)
+			for _, k := range tc.keys {
+				textinput, _ = textinput.Update(tea.KeyMsg{Type: k})
+			}
+			if v := textinput.Value(); v != tc.expected {
+				t.Fatalf("Error: expected value %q but was %q", tc.expected, v)
+			}
+			if pos := textinput.Position(); pos != tc.pos {
+				t.Fatalf("Error: expected cursor at %d but was at %d", tc.pos, pos)
+			}
+		})
+	}
+
+	// The cursor can't be placed inside of a grapheme cluster.
+	textinput := New()
+	textinput.SetValue(family)
+	textinput.SetCursor(2)
+	if pos := textinput.Position(); pos != 0 {
+		t.Fatalf("Error: expected cursor at 0 but was at %d", pos)
+	}
+}
+
+func Test_TokenMode(t *testing.T) {
+	textinput := New()
+	textinput.TokenMode = true
+	textinput.ShowSuggestions = true
+	textinput.SetSuggestions([]string{"Go", "Rust", "Ruby"})
+	textinput.Focus()
+
+	for _, r := range "go,ru" {
+		textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if !reflect.DeepEqual(textinput.MatchedSuggestions(), []string{"Rust", "Ruby"}) {
+		t.Fatalf("Error: unexpected suggestions %v", textinput.MatchedSuggestions())
+	}
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("st")})
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	expected := []string{"Go", "Rust"}
+	if !reflect.DeepEqual(textinput.Tokens(), expected) {
+		t.Fatalf("Error: expected tokens %v but got %v", expected, textinput.Tokens())
+	}
+	if textinput.Value() != "" {
+		t.Fatalf("Error: expected empty value but got %q", textinput.Value())
+	}
+
+	textinput.SetValue("gO")
+	textinput.updateSuggestions()
+	if len(textinput.MatchedSuggestions()) != 0 {
+		t.Fatalf("Error: expected entered tokens not to be suggested, got %v", textinput.MatchedSuggestions())
+	}
+	textinput.SetValue("")
+
+	// The first backspace selects the last token, the second deletes it.
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if !reflect.DeepEqual(textinput.Tokens(), expected) {
+		t.Fatalf("Error: expected tokens %v but got %v", expected, textinput.Tokens())
+	}
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	expected = []string{"Rust"}
+	if !reflect.DeepEqual(textinput.Tokens(), expected) {
+		t.Fatalf("Error: expected tokens %v but got %v", expected, textinput.Tokens())
+	}
+
+	textinput.Reset()
+	if len(textinput.Tokens()) != 0 {
+		t.Fatalf("Error: expected Reset to clear tokens but got %v", textinput.Tokens())
+	}
+}
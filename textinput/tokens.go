@@ -0,0 +1,121 @@
+package textinput
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Tokens returns the tokens entered in TokenMode.
+func (m Model) Tokens() []string {
+	tokens := make([]string, len(m.tokens))
+	copy(tokens, m.tokens)
+	return tokens
+}
+
+// SetTokens replaces the tokens entered in TokenMode. Empty and duplicate
+// tokens are dropped.
+func (m *Model) SetTokens(tokens []string) {
+	m.tokens = nil
+	m.tokenSelected = false
+	for _, t := range tokens {
+		m.addToken(t)
+	}
+	m.updateSuggestions()
+}
+
+// addToken appends a token unless it's empty or already present. If
+// suggestions are shown, a token matching a suggestion takes on its casing.
+func (m *Model) addToken(t string) {
+	t = strings.TrimSpace(t)
+	if t == "" || m.hasToken(t) {
+		return
+	}
+	if m.ShowSuggestions {
+		for _, s := range m.suggestions {
+			if strings.EqualFold(string(s), t) {
+				t = string(s)
+				break
+			}
+		}
+	}
+	m.tokens = append(m.tokens, t)
+}
+
+// hasToken returns whether the given token, ignoring case, has been entered.
+func (m Model) hasToken(t string) bool {
+	for _, tok := range m.tokens {
+		if strings.EqualFold(tok, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeToken removes the token at index i.
+func (m *Model) removeToken(i int) {
+	m.tokens = append(m.tokens[:i], m.tokens[i+1:]...)
+}
+
+// updateTokens handles keys which act on tokens rather than the text. It
+// returns whether the key was handled.
+func (m *Model) updateTokens(msg tea.KeyMsg) bool {
+	if m.tokenSelected {
+		switch {
+		case key.Matches(msg, m.KeyMap.CharacterBackward):
+			m.tokenIndex = max(0, m.tokenIndex-1)
+		case key.Matches(msg, m.KeyMap.CharacterForward):
+			m.tokenIndex++
+			m.tokenSelected = m.tokenIndex < len(m.tokens)
+		case key.Matches(msg, m.KeyMap.DeleteCharacterBackward),
+			key.Matches(msg, m.KeyMap.DeleteCharacterForward):
+			m.removeToken(m.tokenIndex)
+			m.tokenSelected = false
+			m.updateSuggestions()
+		default:
+			// Any other key goes back to editing the text.
+			m.tokenSelected = false
+			return false
+		}
+		return true
+	}
+
+	switch {
+	case key.Matches(msg, m.KeyMap.TokenSeparator):
+		m.addToken(string(m.value))
+		m.value = nil
+		m.SetCursor(0)
+		m.updateSuggestions()
+		return true
+
+	case key.Matches(msg, m.KeyMap.DeleteCharacterBackward) && len(m.value) == 0,
+		key.Matches(msg, m.KeyMap.CharacterBackward) && m.pos == 0:
+		if len(m.tokens) == 0 {
+			return false
+		}
+		m.tokenSelected = true
+		m.tokenIndex = len(m.tokens) - 1
+		return true
+	}
+
+	return false
+}
+
+// tokensView renders the tokens in front of the text.
+func (m Model) tokensView() string {
+	if !m.TokenMode || len(m.tokens) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, t := range m.tokens {
+		if m.tokenSelected && i == m.tokenIndex {
+			b.WriteString(m.SelectedTokenStyle.Render(t))
+		} else {
+			b.WriteString(m.TokenStyle.Render(t))
+		}
+		b.WriteString(" ")
+	}
+	return b.String()
+}